@@ -0,0 +1,271 @@
+package itertools
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+func FromChan[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// ToChan ranges over s in a new goroutine and sends its items on the returned
+// channel, which is closed once s is exhausted. The goroutine exits as soon as
+// ctx is cancelled, even if nothing is reading from the channel.
+func ToChan[T any](ctx context.Context, s iter.Seq[T], buf int) <-chan T {
+	ch := make(chan T, buf)
+
+	go func() {
+		defer close(ch)
+
+		for v := range s {
+			if ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// MergeChans yields items from whichever of chs is ready until all of them are
+// closed. It doesn't start any goroutines, so breaking out of the loop leaves
+// nothing running.
+func MergeChans[T any](chs ...<-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		cases := make([]reflect.SelectCase, 0, len(chs))
+		for _, ch := range chs {
+			cases = append(cases, reflect.SelectCase{
+				Dir:  reflect.SelectRecv,
+				Chan: reflect.ValueOf(ch),
+			})
+		}
+
+		for len(cases) > 0 {
+			i, v, ok := reflect.Select(cases)
+			if !ok {
+				cases = append(cases[:i], cases[i+1:]...)
+				continue
+			}
+
+			if !yield(v.Interface().(T)) {
+				return
+			}
+		}
+	}
+}
+
+// Prefetch ranges over s in a background goroutine that stays up to n items
+// ahead of the consumer, or one if n is less than that. Breaking out of the
+// loop stops the goroutine, and a panic in s is re-raised in the consumer.
+func Prefetch[T any](s iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		// the goroutine holds one item while it waits to send, so the buffer
+		// is one short of n
+		items := make(chan T, max(n-1, 0))
+		panicked := make(chan any, 1)
+		done := make(chan struct{})
+		finished := make(chan struct{})
+
+		go func() {
+			defer close(finished)
+			defer close(items)
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+
+			for v := range s {
+				select {
+				case items <- v:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		defer func() {
+			close(done)
+			<-finished
+		}()
+
+		for v := range items {
+			if !yield(v) {
+				return
+			}
+		}
+
+		select {
+		case p := <-panicked:
+			panic(p)
+		default:
+		}
+	}
+}
+
+// BroadcastPolicy decides what Broadcast does when a consumer's buffer is full.
+type BroadcastPolicy int
+
+const (
+	// BroadcastBlock waits for the slow consumer, holding back the others.
+	BroadcastBlock BroadcastPolicy = iota
+	// BroadcastDrop skips the item for the slow consumer only.
+	BroadcastDrop
+	// BroadcastFail disconnects the slow consumer, whose seq then ends once it
+	// has drained its buffer, and whose error func reports ErrSlowConsumer.
+	BroadcastFail
+)
+
+var ErrSlowConsumer = errors.New("itertools: broadcast consumer fell behind")
+
+type broadcastConsumer[T any] struct {
+	items   chan T
+	done    chan struct{}
+	leave   func()
+	started atomic.Bool
+	failed  bool
+	err     error
+}
+
+// Broadcast returns n seqs that each yield every item of s and can be ranged
+// from separate goroutines. s is ranged once, in a goroutine started by the
+// first consumer, and each consumer may fall up to bufSize items behind before
+// policy applies. A consumer that breaks out of its loop stops receiving
+// without holding up the rest, and s is stopped before the last consumer
+// leaves.
+//
+// Each seq has an error func, to be called after ranging it, that reports
+// whether it was cut off, and a cancel func that drops the consumer without
+// ranging it. A seq that is neither ranged nor cancelled counts as a slow
+// consumer, so under BroadcastBlock it holds up the others.
+func Broadcast[T any](s iter.Seq[T], n, bufSize int, policy BroadcastPolicy) ([]iter.Seq[T], []func() error, []func()) {
+	consumers := make([]*broadcastConsumer[T], n)
+	for i := range consumers {
+		consumers[i] = &broadcastConsumer[T]{
+			items: make(chan T, max(bufSize, 0)),
+			done:  make(chan struct{}),
+		}
+	}
+
+	var start sync.Once
+	var panicked any
+	var left atomic.Int32
+	finished := make(chan struct{})
+
+	for _, c := range consumers {
+		c.leave = sync.OnceFunc(func() {
+			close(c.done)
+			// the last consumer out waits for s to stop, as it's no longer
+			// held up by anyone. If nobody ranged a seq, s never started.
+			if left.Add(1) == int32(n) {
+				start.Do(func() { close(finished) })
+				<-finished
+			}
+		})
+	}
+
+	run := func() {
+		defer close(finished)
+		live := slices.Clone(consumers)
+
+		defer func() {
+			panicked = recover()
+			for _, c := range live {
+				close(c.items)
+			}
+		}()
+
+		for v := range s {
+			live = slices.DeleteFunc(live, func(c *broadcastConsumer[T]) bool {
+				var sent bool
+				switch policy {
+				case BroadcastBlock:
+					select {
+					case c.items <- v:
+						sent = true
+					case <-c.done:
+					}
+				default:
+					select {
+					case <-c.done:
+					case c.items <- v:
+						sent = true
+					default:
+						if policy == BroadcastDrop {
+							return false
+						}
+						c.failed = true
+					}
+				}
+
+				if !sent {
+					close(c.items)
+				}
+				return !sent
+			})
+
+			if len(live) == 0 {
+				return
+			}
+		}
+	}
+
+	seqs := make([]iter.Seq[T], n)
+	for i, c := range consumers {
+		seqs[i] = func(yield func(T) bool) {
+			if c.started.Swap(true) {
+				panic("itertools: Broadcast seq ranged more than once")
+			}
+			select {
+			case <-c.done:
+				return
+			default:
+			}
+			defer c.leave()
+
+			start.Do(func() { go run() })
+
+			for v := range c.items {
+				if !yield(v) {
+					return
+				}
+			}
+
+			// items is closed right after failed is set, or once s is done
+			// and panicked is set
+			if c.failed {
+				c.err = ErrSlowConsumer
+				return
+			}
+			if panicked != nil {
+				panic(panicked)
+			}
+		}
+	}
+
+	errfs := make([]func() error, n)
+	cancels := make([]func(), n)
+	for i, c := range consumers {
+		errfs[i] = func() error { return c.err }
+		cancels[i] = c.leave
+	}
+	return seqs, errfs, cancels
+}
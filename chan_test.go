@@ -0,0 +1,384 @@
+package itertools
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/astonm/go-itertools/itertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromChan(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+
+	assertSequenceMatch(t, FromChan(ch), []int{1, 2, 3})
+
+	ch = make(chan int)
+	itertest.AssertNoGoroutineLeak(t, func() {
+		go func() {
+			defer close(ch)
+			for i := 0; ; i++ {
+				ch <- i
+				if i == 2 {
+					return
+				}
+			}
+		}()
+		assertSequenceMatch(t, FromChan(ch), []int{0, 1, 2})
+	})
+}
+
+func TestToChan(t *testing.T) {
+	var got []int
+	for v := range ToChan(context.Background(), NewSeq(1, 2, 3), 0) {
+		got = append(got, v)
+	}
+	assert.Equal(t, []int{1, 2, 3}, got)
+
+	itertest.AssertNoGoroutineLeak(t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		assertSequenceMatch(t, Take(FromChan(ToChan(ctx, Count(), 2)), 3), []int{0, 1, 2})
+	})
+}
+
+func TestToChanCancel(t *testing.T) {
+	itertest.AssertNoGoroutineLeak(t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := ToChan(ctx, Count(), 0)
+		assert.Equal(t, 0, <-ch)
+		assert.Equal(t, 1, <-ch)
+		cancel()
+	})
+
+	itertest.AssertNoGoroutineLeak(t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		ToChan(ctx, Count(), 5)
+		cancel()
+	})
+}
+
+func TestMergeChans(t *testing.T) {
+	a := ToChan(context.Background(), NewSeq(1, 2, 3), 0)
+	b := ToChan(context.Background(), NewSeq(4, 5), 0)
+	c := make(chan int)
+	close(c)
+
+	got := slices.Collect(MergeChans(a, b, c))
+	slices.Sort(got)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, got)
+
+	assertSequenceMatch(t, MergeChans[int](), []int{})
+}
+
+func TestMergeChansBreak(t *testing.T) {
+	itertest.AssertNoGoroutineLeak(t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		a := ToChan(ctx, Count(), 0)
+		b := ToChan(ctx, Repeat(-1, -1), 0)
+
+		assert.Len(t, slices.Collect(Take(MergeChans(a, b), 10)), 10)
+	})
+}
+
+func TestPrefetch(t *testing.T) {
+	assertSequenceMatch(t, Prefetch(NewSeq(1, 2, 3), 2), []int{1, 2, 3})
+	assertSequenceMatch(t, Prefetch(NewSeq(1, 2, 3), 0), []int{1, 2, 3})
+	assertSequenceMatch(t, Prefetch(NewSeq[int](), 2), []int{})
+
+	itertest.AssertNoGoroutineLeak(t, func() {
+		assertSequenceMatch(t, Take(Prefetch(Count(), 5), 3), []int{0, 1, 2})
+	})
+}
+
+func TestPrefetchStopsEarly(t *testing.T) {
+	var produced int
+	src := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			produced++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	itertest.AssertNoGoroutineLeak(t, func() {
+		var got []int
+		for v := range Prefetch(src, 4) {
+			got = append(got, v)
+			if v == 9 {
+				break
+			}
+		}
+		assert.Equal(t, slices.Collect(Take(Count(), 10)), got)
+	})
+
+	// the consumer took 10, and the goroutine was at most 4 ahead of it
+	assert.LessOrEqual(t, produced, 14)
+}
+
+func TestPrefetchPanic(t *testing.T) {
+	src := func(yield func(int) bool) {
+		yield(1)
+		yield(2)
+		panic("boom")
+	}
+
+	var got []int
+	assert.PanicsWithValue(t, "boom", func() {
+		for v := range Prefetch(src, 1) {
+			got = append(got, v)
+		}
+	})
+	assert.Equal(t, []int{1, 2}, got)
+}
+
+const prefetchLatency = 100 * time.Microsecond
+
+func slowSource(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := range n {
+			time.Sleep(prefetchLatency)
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func slowConsumer(s iter.Seq[int]) {
+	for range s {
+		time.Sleep(prefetchLatency)
+	}
+}
+
+func BenchmarkLockstep(b *testing.B) {
+	for range b.N {
+		slowConsumer(slowSource(100))
+	}
+}
+
+func BenchmarkPrefetch(b *testing.B) {
+	for range b.N {
+		slowConsumer(Prefetch(slowSource(100), 16))
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	for _, policy := range []BroadcastPolicy{BroadcastBlock, BroadcastDrop, BroadcastFail} {
+		itertest.AssertNoGoroutineLeak(t, func() {
+			seqs, errfs, _ := Broadcast(Take(Count(), 100), 3, 200, policy)
+
+			var wg sync.WaitGroup
+			got := make([][]int, len(seqs))
+			for i, s := range seqs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					got[i] = slices.Collect(s)
+				}()
+			}
+			wg.Wait()
+
+			for i := range seqs {
+				assert.Equal(t, slices.Collect(Take(Count(), 100)), got[i], policy)
+				assert.NoError(t, errfs[i]())
+			}
+		})
+	}
+}
+
+func TestBroadcastBlock(t *testing.T) {
+	seqs, _, _ := Broadcast(Take(Count(), 100), 2, 1, BroadcastBlock)
+
+	var wg sync.WaitGroup
+	got := make([][]int, len(seqs))
+	for i, s := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range s {
+				if i == 1 {
+					time.Sleep(time.Microsecond)
+				}
+				got[i] = append(got[i], v)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, got[0], got[1])
+	assert.Len(t, got[0], 100)
+}
+
+func TestBroadcastCancelledConsumer(t *testing.T) {
+	itertest.AssertNoGoroutineLeak(t, func() {
+		seqs, _, _ := Broadcast(Take(Count(), 100), 2, 0, BroadcastBlock)
+
+		var wg sync.WaitGroup
+		var got []int
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assertSequenceMatch(t, Take(seqs[0], 3), []int{0, 1, 2})
+		}()
+		go func() {
+			defer wg.Done()
+			got = slices.Collect(seqs[1])
+		}()
+		wg.Wait()
+
+		assert.Equal(t, slices.Collect(Take(Count(), 100)), got)
+	})
+
+	itertest.AssertNoGoroutineLeak(t, func() {
+		seqs, _, _ := Broadcast(Count(), 2, 0, BroadcastBlock)
+
+		var wg sync.WaitGroup
+		for _, s := range seqs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assertSequenceMatch(t, Take(s, 3), []int{0, 1, 2})
+			}()
+		}
+		wg.Wait()
+	})
+}
+
+func TestBroadcastCancel(t *testing.T) {
+	// the second seq is never ranged, so it must be cancelled for the first
+	// to get past its buffer
+	itertest.AssertNoGoroutineLeak(t, func() {
+		seqs, errfs, cancels := Broadcast(Take(Count(), 100), 2, 4, BroadcastBlock)
+		cancels[1]()
+		cancels[1]()
+
+		assertSequenceMatch(t, seqs[0], slices.Collect(Take(Count(), 100)))
+		assert.NoError(t, errfs[0]())
+		assertSequenceMatch(t, seqs[1], []int{})
+	})
+
+	// with an infinite source, s stops once the ranged seq breaks
+	for _, policy := range []BroadcastPolicy{BroadcastBlock, BroadcastDrop, BroadcastFail} {
+		itertest.AssertNoGoroutineLeak(t, func() {
+			var running atomic.Int32
+			src := func(yield func(int) bool) {
+				running.Add(1)
+				defer running.Add(-1)
+				for i := 0; ; i++ {
+					if !yield(i) {
+						return
+					}
+				}
+			}
+
+			seqs, _, cancels := Broadcast(src, 2, 4, policy)
+			cancels[1]()
+
+			var got []int
+			for v := range seqs[0] {
+				got = append(got, v)
+				if len(got) == 10 {
+					break
+				}
+			}
+			assert.True(t, slices.IsSorted(got), policy)
+			assert.Zero(t, running.Load(), policy)
+		})
+	}
+
+	// cancelling every seq before any is ranged never starts s
+	seqs, _, cancels := Broadcast(Count(), 2, 4, BroadcastBlock)
+	cancels[0]()
+	cancels[1]()
+	assertSequenceMatch(t, seqs[0], []int{})
+}
+
+// slowBroadcast runs a fast consumer to completion while the slow one is stuck
+// on its first item, then lets the slow one finish. The source waits for the
+// fast consumer before each item so that only the slow one falls behind.
+func slowBroadcast(policy BroadcastPolicy, bufSize int) (fast, slow []int, slowErr error) {
+	ack := make(chan struct{})
+	src := func(yield func(int) bool) {
+		for i := range 100 {
+			if !yield(i) {
+				return
+			}
+			<-ack
+		}
+	}
+
+	seqs, errfs, _ := Broadcast(src, 2, bufSize, policy)
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(release)
+		for v := range seqs[0] {
+			fast = append(fast, v)
+			ack <- struct{}{}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for v := range seqs[1] {
+			slow = append(slow, v)
+			<-release
+		}
+		slowErr = errfs[1]()
+	}()
+	wg.Wait()
+
+	return fast, slow, slowErr
+}
+
+func TestBroadcastDrop(t *testing.T) {
+	fast, slow, slowErr := slowBroadcast(BroadcastDrop, 4)
+
+	assert.Equal(t, slices.Collect(Take(Count(), 100)), fast)
+	assert.NoError(t, slowErr)
+	assert.Less(t, len(slow), 100)
+	assert.LessOrEqual(t, len(slow), 6)
+	assert.True(t, slices.IsSorted(slow))
+}
+
+func TestBroadcastFail(t *testing.T) {
+	fast, slow, slowErr := slowBroadcast(BroadcastFail, 4)
+
+	assert.Equal(t, slices.Collect(Take(Count(), 100)), fast)
+	assert.ErrorIs(t, slowErr, ErrSlowConsumer)
+	assert.Equal(t, slices.Collect(Take(Count(), len(slow))), slow)
+	assert.LessOrEqual(t, len(slow), 6)
+}
+
+func TestBroadcastPanic(t *testing.T) {
+	src := func(yield func(int) bool) {
+		yield(1)
+		panic("boom")
+	}
+
+	seqs, _, _ := Broadcast(src, 1, 2, BroadcastBlock)
+	assert.PanicsWithValue(t, "boom", func() {
+		for range seqs[0] {
+		}
+	})
+	assert.Panics(t, func() {
+		for range seqs[0] {
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package itertools
+
+import (
+	"fmt"
+	"iter"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// openIters records where each unfinished iteration started, so leaks can be
+// reported with a stack.
+type openIters struct {
+	mu     sync.Mutex
+	nextID int
+	stacks map[int][]byte
+}
+
+func (o *openIters) add(stack []byte) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.stacks == nil {
+		o.stacks = make(map[int][]byte)
+	}
+	o.nextID++
+	o.stacks[o.nextID] = stack
+	return o.nextID
+}
+
+func (o *openIters) remove(id int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.stacks, id)
+}
+
+func (o *openIters) report(what string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.stacks) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "itertools: %d %s never finished", len(o.stacks), what)
+	for _, stack := range o.stacks {
+		fmt.Fprintf(&b, "\n\nstarted at:\n%s", stack)
+	}
+	return fmt.Errorf("%s", b.String())
+}
+
+var checkedIters openIters
+
+type checker struct {
+	active atomic.Bool
+}
+
+// begin marks the start of an iteration. check wraps each call to yield and
+// end must run once the source returns.
+func (c *checker) begin() (check func(yield func() bool) bool, end func()) {
+	if !c.active.CompareAndSwap(false, true) {
+		panic("itertools: Checked seq ranged again while it was already being ranged")
+	}
+
+	id := checkedIters.add(debug.Stack())
+
+	var stopped bool
+	check = func(yield func() bool) bool {
+		if stopped {
+			panic("itertools: Checked seq called yield after yield returned false")
+		}
+		stopped = !yield()
+		return !stopped
+	}
+
+	end = func() {
+		checkedIters.remove(id)
+		c.active.Store(false)
+	}
+
+	return check, end
+}
+
+// Checked wraps s so that breaking the iterator protocol panics with a clear
+// message: calling yield after it returned false, or ranging s again from
+// inside its own loop. Iterations that never finish, such as a Pull whose stop
+// was forgotten, are reported by CheckStopped. It is meant for tests.
+func Checked[T any](s iter.Seq[T]) iter.Seq[T] {
+	var c checker
+	return func(yield func(T) bool) {
+		check, end := c.begin()
+		defer end()
+
+		s(func(v T) bool {
+			return check(func() bool { return yield(v) })
+		})
+	}
+}
+
+func Checked2[K any, V any](s iter.Seq2[K, V]) iter.Seq2[K, V] {
+	var c checker
+	return func(yield func(K, V) bool) {
+		check, end := c.begin()
+		defer end()
+
+		s(func(k K, v V) bool {
+			return check(func() bool { return yield(k, v) })
+		})
+	}
+}
+
+// CheckStopped panics if any Checked seq has started but not finished, listing
+// the stack each one started from.
+func CheckStopped() {
+	if err := checkedIters.report("Checked iterations"); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,210 @@
+package itertools
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// assertProtocol ranges the seq from mk under Checked, breaking after k items
+// for every k, and checks that no iteration is left running.
+func assertProtocol[T any](t *testing.T, name string, mk func() iter.Seq[T]) {
+	t.Helper()
+
+	for k := range 12 {
+		assert.NotPanics(t, func() {
+			var i int
+			for range Checked(mk()) {
+				if i == k {
+					break
+				}
+				i++
+			}
+			CheckStopped()
+		}, "%s breaking after %d", name, k)
+	}
+}
+
+func assertProtocol2[K any, V any](t *testing.T, name string, mk func() iter.Seq2[K, V]) {
+	t.Helper()
+
+	for k := range 12 {
+		assert.NotPanics(t, func() {
+			var i int
+			for range Checked2(mk()) {
+				if i == k {
+					break
+				}
+				i++
+			}
+			CheckStopped()
+		}, "%s breaking after %d", name, k)
+	}
+}
+
+func TestChecked(t *testing.T) {
+	assertSequenceMatch(t, Checked(NewSeq(1, 2, 3)), []int{1, 2, 3})
+	assertSequenceMatch(t, Take(Checked(Count()), 2), []int{0, 1})
+
+	keys := make([]int, 0)
+	for k := range Checked2(Enumerate(NewSeq("a", "b"))) {
+		keys = append(keys, k)
+	}
+	assert.Equal(t, []int{0, 1}, keys)
+
+	assert.NotPanics(t, CheckStopped)
+}
+
+func TestCheckedYieldAfterFalse(t *testing.T) {
+	ignoresBreak := func(yield func(int) bool) {
+		yield(1)
+		yield(2)
+	}
+
+	assert.PanicsWithValue(t, "itertools: Checked seq called yield after yield returned false", func() {
+		for range Checked(ignoresBreak) {
+			break
+		}
+	})
+
+	ignoresBreak2 := func(yield func(int, int) bool) {
+		yield(1, 1)
+		yield(2, 2)
+	}
+
+	assert.Panics(t, func() {
+		for range Checked2(ignoresBreak2) {
+			break
+		}
+	})
+}
+
+func TestCheckedReentrant(t *testing.T) {
+	s := Checked(NewSeq(1, 2))
+
+	assert.PanicsWithValue(t, "itertools: Checked seq ranged again while it was already being ranged", func() {
+		for range s {
+			for range s {
+			}
+		}
+	})
+
+	// ranging again afterwards is fine
+	assertSequenceMatch(t, s, []int{1, 2})
+}
+
+func TestCheckStopped(t *testing.T) {
+	next, stop := iter.Pull(Checked(Count()))
+	next()
+
+	func() {
+		defer func() {
+			err, ok := recover().(error)
+			assert.True(t, ok)
+			assert.ErrorContains(t, err, "1 Checked iterations never finished")
+			assert.ErrorContains(t, err, "started at")
+		}()
+		CheckStopped()
+	}()
+
+	stop()
+	assert.NotPanics(t, CheckStopped)
+}
+
+func TestCombinatorsChecked(t *testing.T) {
+	nums := func() iter.Seq[int] { return Checked(NewSeq(1, 1, 2, 3, 3, 3, 4, 5)) }
+	count := func() iter.Seq[int] { return Checked(Count()) }
+	vals := []int{1, 2, 3, 4}
+	isOdd := func(x int) bool { return x%2 == 1 }
+
+	assertProtocol(t, "NewSeq", func() iter.Seq[int] { return NewSeq(vals...) })
+	assertProtocol(t, "OfSlice", func() iter.Seq[int] { return OfSlice(vals) })
+	assertProtocol(t, "Map", func() iter.Seq[int] { return Map(func(x int) int { return -x }, nums()) })
+	assertProtocol(t, "Take", func() iter.Seq[int] { return Take(count(), 5) })
+	assertProtocol(t, "Chain", func() iter.Seq[int] { return Chain(nums(), nums(), count()) })
+	assertProtocol(t, "Count", Count)
+	assertProtocol(t, "Cycle", func() iter.Seq[int] { return Cycle(nums()) })
+	assertProtocol(t, "Repeat", func() iter.Seq[int] { return Repeat(1, 5) })
+	assertProtocol(t, "Accumulate", func() iter.Seq[int] {
+		return Accumulate(nums(), func(x, y int) int { return x + y })
+	})
+	assertProtocol(t, "Batched", func() iter.Seq[[]int] { return Batched(nums(), 3) })
+	assertProtocol(t, "Combinations", func() iter.Seq[[]int] { return Combinations(vals, 2) })
+	assertProtocol(t, "Powerset", func() iter.Seq[[]int] { return Powerset(vals) })
+	assertProtocol(t, "SubsetsBetween", func() iter.Seq[[]int] { return SubsetsBetween(vals, 1, 2) })
+	assertProtocol(t, "PowersetMasks", func() iter.Seq[uint64] { return PowersetMasks(4) })
+	assertProtocol(t, "CombinationsWithReplacement", func() iter.Seq[[]int] {
+		return CombinationsWithReplacement(vals, 2)
+	})
+	assertProtocol(t, "DistinctPermutations", func() iter.Seq[[]int] {
+		return DistinctPermutations([]int{1, 1, 2, 3}, 3)
+	})
+	assertProtocol(t, "MultisetCombinations", func() iter.Seq[[]int] {
+		return MultisetCombinations([]int{1, 1, 2, 3}, 2)
+	})
+	assertProtocol(t, "Compress", func() iter.Seq[int] {
+		return Compress(count(), []bool{true, false, true, true, false, true})
+	})
+	assertProtocol(t, "DropWhile", func() iter.Seq[int] {
+		return DropWhile(func(x int) bool { return x < 3 }, nums())
+	})
+	assertProtocol(t, "FilterFalse", func() iter.Seq[int] { return FilterFalse(isOdd, count()) })
+	assertProtocol(t, "Slice", func() iter.Seq[int] { return Slice(count(), 2, 8) })
+	assertProtocol(t, "Permutations", func() iter.Seq[[]int] { return Permutations(vals, 2) })
+	assertProtocol(t, "Product", func() iter.Seq[[]int] { return Product(vals, vals) })
+	assertProtocol(t, "ProductRepeat", func() iter.Seq[[]int] { return ProductRepeat(vals, 2) })
+	assertProtocol(t, "ProductDiagonal", func() iter.Seq[[]int] {
+		return ProductDiagonal(count(), nums(), count())
+	})
+	assertProtocol(t, "SetPartitions", func() iter.Seq[[][]int] { return SetPartitions(vals) })
+	assertProtocol(t, "IntegerPartitions", func() iter.Seq[[]int] { return IntegerPartitions(6) })
+	assertProtocol(t, "Compositions", func() iter.Seq[[]int] { return Compositions(6, 3) })
+	assertProtocol(t, "TakeWhile", func() iter.Seq[int] {
+		return TakeWhile(func(x int) bool { return x < 10 }, count())
+	})
+	assertProtocol(t, "ShuffleBuffered", func() iter.Seq[int] {
+		return ShuffleBuffered(Take(count(), 20), 4, newRand())
+	})
+	assertProtocol(t, "Shuffle", func() iter.Seq[int] { return Shuffle(nums(), newRand()) })
+	assertProtocol(t, "FromChan", func() iter.Seq[int] {
+		ch := make(chan int, 20)
+		for v := range nums() {
+			ch <- v
+		}
+		close(ch)
+		return FromChan(ch)
+	})
+	assertProtocol(t, "MergeChans", func() iter.Seq[int] {
+		ch := make(chan int, 20)
+		for v := range nums() {
+			ch <- v
+		}
+		close(ch)
+		return MergeChans(ch)
+	})
+	assertProtocol(t, "Prefetch", func() iter.Seq[int] { return Prefetch(count(), 3) })
+	assertProtocol(t, "GroupBy groups", func() iter.Seq[int] {
+		return func(yield func(int) bool) {
+			for _, g := range GroupBy(nums()) {
+				for v := range Checked(g) {
+					if !yield(v) {
+						return
+					}
+				}
+			}
+		}
+	})
+
+	assertProtocol2(t, "Enumerate", func() iter.Seq2[int, int] { return Enumerate(count()) })
+	assertProtocol2(t, "GroupBy", func() iter.Seq2[int, iter.Seq[int]] { return GroupBy(nums()) })
+	assertProtocol2(t, "Pairwise", func() iter.Seq2[int, int] { return Pairwise(nums()) })
+	assertProtocol2(t, "Zip", func() iter.Seq2[int, int] { return Zip(nums(), count()) })
+	assertProtocol2(t, "Tee", func() iter.Seq2[int, int] {
+		seqs, stop := Tee(nums(), 2)
+		return func(yield func(int, int) bool) {
+			defer stop()
+			Zip(seqs[0], seqs[1])(yield)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package itertools
+
+import (
+	"context"
+	"iter"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/astonm/go-itertools/itertest"
+)
+
+// seqCase builds a seq under test from the instrumented source itertest
+// provides, and says whether ranging it twice should give the same items.
+type seqCase struct {
+	name       string
+	reiterable bool
+	mk         func(src iter.Seq[int]) iter.Seq[any]
+}
+
+func anySeq[T any](s iter.Seq[T]) iter.Seq[any] {
+	return Map(func(v T) any { return v }, s)
+}
+
+func anySeq2[K any, V any](s iter.Seq2[K, V]) iter.Seq[any] {
+	return func(yield func(any) bool) {
+		for k, v := range s {
+			if !yield([2]any{k, v}) {
+				return
+			}
+		}
+	}
+}
+
+func conformanceCases() []seqCase {
+	vals := []int{1, 2, 3, 4}
+	isOdd := func(x int) bool { return x%2 == 1 }
+	sum := func(x, y int) int { return x + y }
+	text := "a1 b22 c333, d4444\ne5"
+	digits := regexp.MustCompile(`(\d)\d*`)
+
+	return []seqCase{
+		{"NewSeq", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(NewSeq(vals...)) }},
+		{"OfSlice", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(OfSlice(vals)) }},
+		{"Enumerate", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq2(Enumerate(src)) }},
+		{"Map", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Map(isOdd, src)) }},
+		{"Take", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Take(src, 5)) }},
+		{"Chain", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Chain(Take(src, 3), src)) }},
+		{"Count", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Count()) }},
+		{"Cycle", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Cycle(Take(src, 3))) }},
+		{"Repeat", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Repeat(1, 5)) }},
+		{"Iterate", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(Iterate(1, func(x int) int { return x * 2 }))
+		}},
+		{"Unfold", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(Unfold(10, func(n int) (int, int, bool) { return n, n - 1, n > 0 }))
+		}},
+		{"Generate", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Generate(func() int { return 1 })) }},
+		{"Recurrence", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(Recurrence([]int{0, 1}, func(w []int) int { return w[0] + w[1] }))
+		}},
+		{"Accumulate", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Accumulate(src, sum)) }},
+		{"Batched", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Batched(src, 3)) }},
+		{"Combinations", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Combinations(vals, 2)) }},
+		{"Powerset", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Powerset(vals)) }},
+		{"SubsetsBetween", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(SubsetsBetween(vals, 1, 2)) }},
+		{"PowersetMasks", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(PowersetMasks(4)) }},
+		{"CombinationsWithReplacement", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(CombinationsWithReplacement(vals, 2))
+		}},
+		{"DistinctPermutations", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(DistinctPermutations([]int{1, 1, 2}, 3))
+		}},
+		{"DistinctPermutationsFunc", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(DistinctPermutationsFunc([]int{1, 1, 2}, 2, func(a, b int) int { return b - a }))
+		}},
+		{"MultisetCombinations", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(MultisetCombinations([]int{1, 1, 2, 3}, 2))
+		}},
+		{"MultisetCombinationsFunc", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(MultisetCombinationsFunc([]int{1, 1, 2, 3}, 2, func(a, b int) int { return b - a }))
+		}},
+		{"Compress", true, func(src iter.Seq[int]) iter.Seq[any] {
+			return anySeq(Compress(src, []bool{true, false, true, true}))
+		}},
+		{"DropWhile", true, func(src iter.Seq[int]) iter.Seq[any] {
+			return anySeq(DropWhile(func(x int) bool { return x < 3 }, src))
+		}},
+		{"FilterFalse", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(FilterFalse(isOdd, src)) }},
+		{"GroupBy", true, func(src iter.Seq[int]) iter.Seq[any] {
+			return func(yield func(any) bool) {
+				for k, g := range GroupBy(Map(func(x int) int { return x / 3 }, src)) {
+					if !yield([2]any{k, slices.Collect(g)}) {
+						return
+					}
+				}
+			}
+		}},
+		{"Slice", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Slice(src, 2, 8)) }},
+		{"Pairwise", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq2(Pairwise(src)) }},
+		{"Permutations", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Permutations(vals, 2)) }},
+		{"Product", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Product(vals, vals)) }},
+		{"ProductRepeat", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(ProductRepeat(vals, 2)) }},
+		{"ProductDiagonal", true, func(src iter.Seq[int]) iter.Seq[any] {
+			return anySeq(ProductDiagonal(src, Take(src, 3), Count()))
+		}},
+		{"SetPartitions", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(SetPartitions(vals)) }},
+		{"IntegerPartitions", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(IntegerPartitions(6)) }},
+		{"Compositions", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Compositions(6, 3)) }},
+		{"TakeWhile", true, func(src iter.Seq[int]) iter.Seq[any] {
+			return anySeq(TakeWhile(func(x int) bool { return x < 10 }, src))
+		}},
+		{"Tee", false, func(src iter.Seq[int]) iter.Seq[any] {
+			seqs, stop := Tee(src, 2)
+			return func(yield func(any) bool) {
+				defer stop()
+				anySeq2(Zip(seqs[0], seqs[1]))(yield)
+			}
+		}},
+		{"Zip", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq2(Zip(src, Count())) }},
+		{"PullZip3", true, func(src iter.Seq[int]) iter.Seq[any] {
+			return func(yield func(any) bool) {
+				next, stop := PullZip3(src, Count(), Take(src, 5))
+				defer stop()
+				for {
+					a, b, c, ok := next()
+					if !ok || !yield([3]any{a, b, c}) {
+						return
+					}
+				}
+			}
+		}},
+		{"PullZip4", true, func(src iter.Seq[int]) iter.Seq[any] {
+			return func(yield func(any) bool) {
+				next, stop := PullZip4(src, Count(), Take(src, 5), Repeat("x", 4))
+				defer stop()
+				for {
+					a, b, c, d, ok := next()
+					if !ok || !yield([4]any{a, b, c, d}) {
+						return
+					}
+				}
+			}
+		}},
+		{"ShuffleBuffered", false, func(src iter.Seq[int]) iter.Seq[any] {
+			return anySeq(ShuffleBuffered(src, 4, newRand()))
+		}},
+		{"Shuffle", false, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Shuffle(src, newRand())) }},
+		{"FromChan", false, func(src iter.Seq[int]) iter.Seq[any] {
+			ch := make(chan int, 10)
+			for v := range Take(src, 10) {
+				ch <- v
+			}
+			close(ch)
+			return anySeq(FromChan(ch))
+		}},
+		{"MergeChans", false, func(src iter.Seq[int]) iter.Seq[any] {
+			ch := make(chan int, 10)
+			for v := range Take(src, 10) {
+				ch <- v
+			}
+			close(ch)
+			return anySeq(MergeChans(ch))
+		}},
+		{"Prefetch", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Prefetch(src, 3)) }},
+		{"Broadcast", false, func(src iter.Seq[int]) iter.Seq[any] {
+			seqs, _, _ := Broadcast(src, 2, 2, BroadcastBlock)
+			return anySeq2(Zip(seqs[0], seqs[1]))
+		}},
+		{"Peekable", false, func(src iter.Seq[int]) iter.Seq[any] {
+			p := NewPeekable(Take(src, 10))
+			return func(yield func(any) bool) {
+				defer p.Stop()
+				for v := range p.Seq() {
+					if !yield(v) {
+						return
+					}
+				}
+			}
+		}},
+		{"Memoize", true, func(src iter.Seq[int]) iter.Seq[any] {
+			s, stop := Memoize(src)
+			return func(yield func(any) bool) {
+				defer stop()
+				for v := range s {
+					if !yield(v) {
+						return
+					}
+				}
+			}
+		}},
+		{"Once", false, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Once(src)) }},
+		{"Checked", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq(Checked(src)) }},
+		{"Checked2", true, func(src iter.Seq[int]) iter.Seq[any] { return anySeq2(Checked2(Enumerate(src))) }},
+		{"Matches", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Matches(digits, text)) }},
+		{"SubmatchIndexes", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(SubmatchIndexes(digits, text)) }},
+		{"SplitRegexp", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(SplitRegexp(digits, text)) }},
+		{"SplitSeq", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(SplitSeq(text, " ")) }},
+		{"FieldsSeq", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(FieldsSeq([]byte(text))) }},
+		{"FieldsFuncSeq", true, func(iter.Seq[int]) iter.Seq[any] {
+			return anySeq(FieldsFuncSeq(text, func(r rune) bool { return r == ',' }))
+		}},
+		{"LinesSeq", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(LinesSeq(text)) }},
+		{"RunesSeq", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(RunesSeq([]byte(text))) }},
+		{"Words", true, func(iter.Seq[int]) iter.Seq[any] { return anySeq(Words(text)) }},
+	}
+}
+
+func TestConformance(t *testing.T) {
+	for _, c := range conformanceCases() {
+		t.Run(c.name, func(t *testing.T) {
+			itertest.AssertNoGoroutineLeak(t, func() {
+				itertest.AssertStopsEarly(t, c.mk)
+
+				if c.reiterable {
+					itertest.AssertReiterable(t, c.mk(NewSeq(1, 2, 3, 4, 5, 6)))
+				}
+			})
+		})
+	}
+}
+
+// ToChan's goroutine exits asynchronously once its context is cancelled, so it
+// is only checked for leaks.
+func TestConformanceToChan(t *testing.T) {
+	for k := range 5 {
+		itertest.AssertNoGoroutineLeak(t, func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			itertest.EqualPrefix(t, FromChan(ToChan(ctx, Count(), k)), slices.Collect(Take(Count(), k)))
+		})
+	}
+}
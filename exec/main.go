@@ -338,12 +338,14 @@ func test_take_while() {
 }
 
 func test_tee() {
-	// idk what this is doing??
-	// why is this printing ints?
 	fruits := itertools.OfSlice(get_fruits())
 
-	for v := range itertools.Tee(fruits, 2) {
-		println(v)
+	seqs, stop := itertools.Tee(fruits, 2)
+	defer stop()
+	for _, s := range seqs {
+		for v := range s {
+			println(v)
+		}
 	}
 }
 
@@ -363,7 +365,8 @@ func test_pullzip3() {
 	// why return 2 here?
 	// this API took me a while to figure this out lol
 	// is there a simpler way to do this?
-	fn1, _ := itertools.PullZip3(fruits, numbers, romans)
+	fn1, stop := itertools.PullZip3(fruits, numbers, romans)
+	defer stop()
 	for true {
 		v1, v2, v3, b := fn1()
 		println(v1, v2, v3, b)
@@ -379,7 +382,8 @@ func test_pullzip4() {
 	romans := itertools.OfSlice(get_romans())
 	starks := itertools.OfSlice(get_starks())
 
-	fn1, _ := itertools.PullZip4(fruits, numbers, romans, starks)
+	fn1, stop := itertools.PullZip4(fruits, numbers, romans, starks)
+	defer stop()
 	for true {
 		v1, v2, v3, v4, b := fn1()
 		println(v1, v2, v3, v4, b)
@@ -0,0 +1,111 @@
+package iterio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// ErrStaleEntry is returned when reading an archive entry after the loop has
+// moved past it.
+var ErrStaleEntry = errors.New("archive entry read after iteration moved on")
+
+// entryReader reads one archive entry until the loop moves on.
+type entryReader struct {
+	name  string
+	open  func() (io.Reader, error)
+	r     io.Reader
+	err   error
+	stale bool
+}
+
+func (e *entryReader) Read(p []byte) (int, error) {
+	if e.stale {
+		return 0, fmt.Errorf("%s: %w", e.name, ErrStaleEntry)
+	}
+
+	if e.r == nil && e.err == nil {
+		e.r, e.err = e.open()
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+	return e.r.Read(p)
+}
+
+func (e *entryReader) close() {
+	e.stale = true
+	if c, ok := e.r.(io.Closer); ok {
+		c.Close()
+	}
+}
+
+// TarEntries yields the header and contents of each entry in the tar archive
+// in r, which may be gzipped. The reader is only valid until the next
+// iteration.
+func TarEntries(r io.Reader) (iter.Seq2[*tar.Header, io.Reader], func() error) {
+	var err error
+
+	seq := func(yield func(*tar.Header, io.Reader) bool) {
+		br := bufio.NewReader(r)
+		var src io.Reader = br
+		if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+			zr, gzErr := gzip.NewReader(br)
+			if gzErr != nil {
+				err = gzErr
+				return
+			}
+			defer zr.Close()
+			src = zr
+		}
+
+		tr := tar.NewReader(src)
+		for {
+			hdr, nextErr := tr.Next()
+			if errors.Is(nextErr, io.EOF) {
+				return
+			}
+			if nextErr != nil {
+				err = nextErr
+				return
+			}
+
+			entry := &entryReader{name: hdr.Name, r: tr}
+			ok := yield(hdr, entry)
+			entry.close()
+			if !ok {
+				return
+			}
+		}
+	}
+
+	return seq, func() error { return err }
+}
+
+// ZipEntries yields the header and contents of each file in zr. Files are
+// only opened when read, and the reader is only valid until the next
+// iteration.
+func ZipEntries(zr *zip.Reader) (iter.Seq2[*zip.FileHeader, io.Reader], func() error) {
+	seq := func(yield func(*zip.FileHeader, io.Reader) bool) {
+		for _, f := range zr.File {
+			entry := &entryReader{
+				name: f.Name,
+				open: func() (io.Reader, error) { return f.Open() },
+			}
+			ok := yield(&f.FileHeader, entry)
+			entry.close()
+			if !ok {
+				return
+			}
+		}
+	}
+
+	// zip.NewReader has already read the directory, so only reading entries
+	// can fail, and those errors come from the entry readers.
+	return seq, func() error { return nil }
+}
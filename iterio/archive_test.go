@@ -0,0 +1,160 @@
+package iterio
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var archiveFiles = []struct{ name, body string }{
+	{"a.txt", "alpha"},
+	{"dir/b.txt", "bravo bravo"},
+	{"empty", ""},
+}
+
+func makeTar(t *testing.T) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range archiveFiles {
+		assert.NoError(t, tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0o644, Size: int64(len(f.body))}))
+		_, err := tw.Write([]byte(f.body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T) *zip.Reader {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range archiveFiles {
+		w, err := zw.Create(f.name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(f.body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	return zr
+}
+
+func gzipped(t *testing.T, b []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write(b)
+	assert.NoError(t, err)
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestTarEntries(t *testing.T) {
+	raw := makeTar(t)
+	for name, b := range map[string][]byte{"plain": raw, "gzip": gzipped(t, raw)} {
+		t.Run(name, func(t *testing.T) {
+			entries, errf := TarEntries(bytes.NewReader(b))
+
+			got := map[string]string{}
+			for hdr, r := range entries {
+				body, err := io.ReadAll(r)
+				assert.NoError(t, err)
+				got[hdr.Name] = string(body)
+			}
+			assert.NoError(t, errf())
+			assert.Equal(t, map[string]string{"a.txt": "alpha", "dir/b.txt": "bravo bravo", "empty": ""}, got)
+		})
+	}
+
+	entries, errf := TarEntries(bytes.NewReader(nil))
+	for range entries {
+		t.Fatal("empty input has no entries")
+	}
+	assert.NoError(t, errf())
+}
+
+func TestTarEntriesSkipsUnread(t *testing.T) {
+	entries, errf := TarEntries(bytes.NewReader(makeTar(t)))
+
+	var names []string
+	for hdr := range entries {
+		names = append(names, hdr.Name)
+	}
+	assert.NoError(t, errf())
+	assert.Equal(t, []string{"a.txt", "dir/b.txt", "empty"}, names)
+}
+
+func TestTarEntriesStale(t *testing.T) {
+	entries, errf := TarEntries(bytes.NewReader(makeTar(t)))
+
+	var first io.Reader
+	for _, r := range entries {
+		if first == nil {
+			first = r
+		}
+	}
+	assert.NoError(t, errf())
+
+	_, err := io.ReadAll(first)
+	assert.ErrorIs(t, err, ErrStaleEntry)
+	assert.ErrorContains(t, err, "a.txt")
+}
+
+func TestTarEntriesError(t *testing.T) {
+	raw := makeTar(t)
+	entries, errf := TarEntries(bytes.NewReader(raw[:1541]))
+
+	var names []string
+	for hdr := range entries {
+		names = append(names, hdr.Name)
+	}
+	assert.Equal(t, []string{"a.txt", "dir/b.txt"}, names)
+	assert.ErrorIs(t, errf(), io.ErrUnexpectedEOF)
+
+	entries, errf = TarEntries(bytes.NewReader([]byte{0x1f, 0x8b, 0}))
+	for range entries {
+		t.Fatal("corrupt gzip has no entries")
+	}
+	assert.Error(t, errf())
+}
+
+func TestZipEntries(t *testing.T) {
+	entries, errf := ZipEntries(makeZip(t))
+
+	got := map[string]string{}
+	for hdr, r := range entries {
+		body, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		got[hdr.Name] = string(body)
+	}
+	assert.NoError(t, errf())
+	assert.Equal(t, map[string]string{"a.txt": "alpha", "dir/b.txt": "bravo bravo", "empty": ""}, got)
+}
+
+func TestZipEntriesStale(t *testing.T) {
+	entries, _ := ZipEntries(makeZip(t))
+
+	var readers []io.Reader
+	for hdr, r := range entries {
+		if hdr.Name == "a.txt" {
+			// partly read, so the file is open when the loop moves on
+			buf := make([]byte, 2)
+			_, err := r.Read(buf)
+			assert.NoError(t, err)
+		}
+		readers = append(readers, r)
+		if len(readers) == 2 {
+			break
+		}
+	}
+
+	for _, r := range readers {
+		_, err := r.Read(make([]byte, 1))
+		assert.ErrorIs(t, err, ErrStaleEntry)
+	}
+}
@@ -0,0 +1,203 @@
+package iterio
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"os/exec"
+	"sync"
+)
+
+// CommandLines starts cmd and yields the lines it writes to stdout. Unless
+// cmd.Stderr is already set, stderr is collected into the *exec.ExitError the
+// func returns if the command fails.
+//
+// Breaking out of the loop, or cancelling ctx, kills the process. After a
+// break the func returns nil, and after a cancel it returns ctx.Err().
+// If reading stdout fails, the process is killed and the func returns that
+// error. Otherwise it returns the error from cmd.Wait.
+//
+// Killing the process doesn't kill any children it started. If they hold on to
+// its output, set cmd.WaitDelay to bound how long the seq waits for them.
+func CommandLines(ctx context.Context, cmd *exec.Cmd) (iter.Seq[string], func() error) {
+	var err error
+
+	seq := func(yield func(string) bool) {
+		var stderr *bytes.Buffer
+		if cmd.Stderr == nil {
+			stderr = &bytes.Buffer{}
+			cmd.Stderr = stderr
+		}
+
+		stdout, pipeErr := cmd.StdoutPipe()
+		if pipeErr != nil {
+			err = pipeErr
+			return
+		}
+
+		if startErr := cmd.Start(); startErr != nil {
+			err = startErr
+			return
+		}
+		stopKill := context.AfterFunc(ctx, func() { cmd.Process.Kill() })
+
+		var readErr error
+		broke := true
+		defer func() {
+			// the process must not outlive the loop, and if stdout can't be
+			// read it would block on a full pipe and never exit
+			if broke || readErr != nil {
+				cmd.Process.Kill()
+				// children of the process may still hold stdout, and Wait
+				// won't close it until they let go of stderr
+				stdout.Close()
+			}
+			stopKill()
+			waitErr := cmd.Wait()
+
+			switch {
+			case broke:
+				err = nil
+			case ctx.Err() != nil:
+				err = ctx.Err()
+			case readErr != nil:
+				err = readErr
+			case waitErr != nil:
+				var exitErr *exec.ExitError
+				if stderr != nil && errors.As(waitErr, &exitErr) {
+					exitErr.Stderr = stderr.Bytes()
+				}
+				err = waitErr
+			}
+		}()
+
+		lines, linesErr := Lines(stdout)
+		for line := range lines {
+			if !yield(line) {
+				return
+			}
+		}
+		broke = false
+		readErr = linesErr()
+	}
+
+	return seq, func() error { return err }
+}
+
+// Stream says which output of a command a line came from.
+type Stream int
+
+const (
+	Stdout Stream = iota + 1
+	Stderr
+)
+
+func (s Stream) String() string {
+	switch s {
+	case Stdout:
+		return "stdout"
+	case Stderr:
+		return "stderr"
+	}
+	return "unknown"
+}
+
+// OutputLine is a line of command output, see CommandOutputLines.
+type OutputLine struct {
+	Stream Stream
+	Text   string
+}
+
+// CommandOutputLines is like CommandLines, but yields the lines of both
+// stdout and stderr in the order they arrive, tagged with where they came
+// from.
+func CommandOutputLines(ctx context.Context, cmd *exec.Cmd) (iter.Seq[OutputLine], func() error) {
+	var err error
+
+	seq := func(yield func(OutputLine) bool) {
+		stdout, pipeErr := cmd.StdoutPipe()
+		if pipeErr != nil {
+			err = pipeErr
+			return
+		}
+		stderr, pipeErr := cmd.StderrPipe()
+		if pipeErr != nil {
+			err = pipeErr
+			return
+		}
+
+		if startErr := cmd.Start(); startErr != nil {
+			err = startErr
+			return
+		}
+		stopKill := context.AfterFunc(ctx, func() { cmd.Process.Kill() })
+
+		lines := make(chan OutputLine)
+		done := make(chan struct{})
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			readErr error
+		)
+		read := func(stream Stream, r io.ReadCloser) {
+			defer wg.Done()
+			sc := bufio.NewScanner(r)
+			for sc.Scan() {
+				select {
+				case lines <- OutputLine{stream, sc.Text()}:
+				case <-done:
+					return
+				}
+			}
+			if scanErr := sc.Err(); scanErr != nil {
+				mu.Lock()
+				readErr = errors.Join(readErr, scanErr)
+				mu.Unlock()
+				// nothing drains this pipe now, so the process would block
+				cmd.Process.Kill()
+				r.Close()
+			}
+		}
+		wg.Add(2)
+		go read(Stdout, stdout)
+		go read(Stderr, stderr)
+		go func() {
+			wg.Wait()
+			close(lines)
+		}()
+
+		broke := true
+		defer func() {
+			close(done)
+			if broke {
+				cmd.Process.Kill()
+			}
+			stopKill()
+			waitErr := cmd.Wait()
+			wg.Wait()
+
+			switch {
+			case broke:
+				err = nil
+			case ctx.Err() != nil:
+				err = ctx.Err()
+			case readErr != nil:
+				err = readErr
+			default:
+				err = waitErr
+			}
+		}()
+
+		for line := range lines {
+			if !yield(line) {
+				return
+			}
+		}
+		broke = false
+	}
+
+	return seq, func() error { return err }
+}
@@ -0,0 +1,131 @@
+package iterio
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/astonm/go-itertools/itertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func sh(script string) *exec.Cmd {
+	return exec.Command("sh", "-c", script)
+}
+
+func TestCommandLines(t *testing.T) {
+	lines, errf := CommandLines(context.Background(), sh("printf 'one\\ntwo\\n\\nthree'; echo oops >&2"))
+	itertest.Equal(t, lines, []string{"one", "two", "", "three"})
+	assert.NoError(t, errf())
+}
+
+func TestCommandLinesExitStatus(t *testing.T) {
+	lines, errf := CommandLines(context.Background(), sh("echo one; echo bad things >&2; exit 3"))
+	itertest.Equal(t, lines, []string{"one"})
+
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, errf(), &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+	assert.Equal(t, "bad things\n", string(exitErr.Stderr))
+
+	lines, errf = CommandLines(context.Background(), exec.Command("/does/not/exist"))
+	itertest.Equal(t, lines, []string{})
+	assert.Error(t, errf())
+}
+
+func TestCommandLinesBreak(t *testing.T) {
+	cmd := exec.Command("yes")
+	lines, errf := CommandLines(context.Background(), cmd)
+
+	var got []string
+	for line := range lines {
+		got = append(got, line)
+		if len(got) == 3 {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"y", "y", "y"}, got)
+	assert.NoError(t, errf())
+	assert.NotNil(t, cmd.ProcessState)
+}
+
+func TestCommandLinesCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lines, errf := CommandLines(ctx, sh("echo ready; exec sleep 10"))
+
+	start := time.Now()
+	for line := range lines {
+		assert.Equal(t, "ready", line)
+		cancel()
+	}
+
+	assert.ErrorIs(t, errf(), context.Canceled)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestCommandOutputLines(t *testing.T) {
+	// the script waits for a line on stdin before each write, so the order
+	// lines arrive in is fixed
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	cmd := sh("echo out1; read x; echo err1 >&2; read x; echo out2; exit 2")
+	cmd.Stdin = r
+	lines, errf := CommandOutputLines(context.Background(), cmd)
+
+	var got []OutputLine
+	for line := range lines {
+		got = append(got, line)
+		_, err := w.WriteString("\n")
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []OutputLine{
+		{Stdout, "out1"},
+		{Stderr, "err1"},
+		{Stdout, "out2"},
+	}, got)
+
+	var exitErr *exec.ExitError
+	assert.ErrorAs(t, errf(), &exitErr)
+	assert.Equal(t, 2, exitErr.ExitCode())
+	assert.Equal(t, "stderr", Stderr.String())
+}
+
+// longLine writes a line too long for bufio.Scanner, then more than a pipe
+// holds, so the command can only exit if it's killed or drained.
+const longLine = "head -c 200000 /dev/zero | tr '\\0' a; echo; head -c 1000000 /dev/zero"
+
+func TestCommandLinesReadError(t *testing.T) {
+	lines, errf := CommandLines(context.Background(), sh(longLine))
+	itertest.Equal(t, lines, []string{})
+	assert.ErrorIs(t, errf(), bufio.ErrTooLong)
+
+	lines2, errf := CommandOutputLines(context.Background(), sh("echo ok; ("+longLine+") >&2"))
+	itertest.Equal(t, lines2, []OutputLine{{Stdout, "ok"}})
+	assert.ErrorIs(t, errf(), bufio.ErrTooLong)
+}
+
+func TestCommandOutputLinesBreak(t *testing.T) {
+	cmd := sh("exec yes")
+	lines, errf := CommandOutputLines(context.Background(), cmd)
+
+	var got []OutputLine
+	for line := range lines {
+		got = append(got, line)
+		if len(got) == 3 {
+			break
+		}
+	}
+
+	assert.Equal(t, []OutputLine{{Stdout, "y"}, {Stdout, "y"}, {Stdout, "y"}}, got)
+	assert.NoError(t, errf())
+	assert.NotNil(t, cmd.ProcessState)
+}
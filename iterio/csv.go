@@ -0,0 +1,104 @@
+package iterio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// CSVOptions are passed through to csv.Reader. The zero value reads standard
+// comma separated values.
+type CSVOptions struct {
+	Comma            rune
+	Comment          rune
+	FieldsPerRecord  int
+	LazyQuotes       bool
+	TrimLeadingSpace bool
+	// ReuseRecord yields the same slice for every record, so a record is only
+	// valid until the loop moves on.
+	ReuseRecord bool
+}
+
+func (o CSVOptions) reader(r io.Reader) *csv.Reader {
+	cr := csv.NewReader(r)
+	if o.Comma != 0 {
+		cr.Comma = o.Comma
+	}
+	cr.Comment = o.Comment
+	cr.FieldsPerRecord = o.FieldsPerRecord
+	cr.LazyQuotes = o.LazyQuotes
+	cr.TrimLeadingSpace = o.TrimLeadingSpace
+	cr.ReuseRecord = o.ReuseRecord
+	return cr
+}
+
+// ReadCSV yields the records of r.
+func ReadCSV(r io.Reader, opts CSVOptions) (iter.Seq[[]string], func() error) {
+	var err error
+
+	seq := func(yield func([]string) bool) {
+		cr := opts.reader(r)
+		for {
+			record, readErr := cr.Read()
+			if readErr != nil {
+				if !errors.Is(readErr, io.EOF) {
+					err = readErr
+				}
+				return
+			}
+			if !yield(record) {
+				return
+			}
+		}
+	}
+
+	return seq, func() error { return err }
+}
+
+// ReadCSVRows reads the first record of r as a header and yields every
+// following record as a map from column name to value.
+func ReadCSVRows(r io.Reader, opts CSVOptions) (iter.Seq[map[string]string], func() error) {
+	// the header has to outlive the first record
+	opts.ReuseRecord = false
+	records, errf := ReadCSV(r, opts)
+
+	var err error
+	seq := func(yield func(map[string]string) bool) {
+		var header []string
+		for record := range records {
+			if header == nil {
+				header = record
+				continue
+			}
+
+			if len(record) != len(header) {
+				err = fmt.Errorf("record has %d fields but the header has %d", len(record), len(header))
+				return
+			}
+
+			row := make(map[string]string, len(header))
+			for i, name := range header {
+				row[name] = record[i]
+			}
+			if !yield(row) {
+				return
+			}
+		}
+	}
+
+	return seq, func() error { return errors.Join(errf(), err) }
+}
+
+// WriteCSV writes every record of seq to w.
+func WriteCSV(w io.Writer, seq iter.Seq[[]string]) error {
+	cw := csv.NewWriter(w)
+	for record := range seq {
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,74 @@
+package iterio
+
+import (
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/astonm/go-itertools"
+	"github.com/astonm/go-itertools/itertest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCSV(t *testing.T) {
+	records, errf := ReadCSV(strings.NewReader("a,b\n1,\"x,y\"\n2,z\n"), CSVOptions{})
+	itertest.Equal(t, records, [][]string{{"a", "b"}, {"1", "x,y"}, {"2", "z"}})
+	assert.NoError(t, errf())
+
+	records, errf = ReadCSV(strings.NewReader("# comment\na;b\n"), CSVOptions{Comma: ';', Comment: '#'})
+	itertest.Equal(t, records, [][]string{{"a", "b"}})
+	assert.NoError(t, errf())
+}
+
+func TestReadCSVError(t *testing.T) {
+	records, errf := ReadCSV(strings.NewReader("a,b\n1,\"x\n"), CSVOptions{})
+	itertest.Equal(t, records, [][]string{{"a", "b"}})
+
+	var parseErr *csv.ParseError
+	assert.ErrorAs(t, errf(), &parseErr)
+	assert.Equal(t, 2, parseErr.StartLine)
+
+	records, errf = ReadCSV(failingReader("a,b\n"), CSVOptions{})
+	itertest.Equal(t, records, [][]string{{"a", "b"}})
+	assert.ErrorIs(t, errf(), errBroken)
+}
+
+func TestReadCSVReuseRecord(t *testing.T) {
+	records, errf := ReadCSV(strings.NewReader("a,b\nc,d\n"), CSVOptions{ReuseRecord: true})
+
+	var joined []string
+	for record := range records {
+		joined = append(joined, strings.Join(record, ""))
+	}
+	assert.Equal(t, []string{"ab", "cd"}, joined)
+	assert.NoError(t, errf())
+}
+
+func TestReadCSVRows(t *testing.T) {
+	rows, errf := ReadCSVRows(strings.NewReader("name,age\nann,31\nbob,42\n"), CSVOptions{ReuseRecord: true})
+	itertest.Equal(t, rows, []map[string]string{
+		{"name": "ann", "age": "31"},
+		{"name": "bob", "age": "42"},
+	})
+	assert.NoError(t, errf())
+
+	rows, errf = ReadCSVRows(strings.NewReader("name,age\nann\n"), CSVOptions{FieldsPerRecord: -1})
+	itertest.Equal(t, rows, []map[string]string{})
+	assert.EqualError(t, errf(), "record has 1 fields but the header has 2")
+
+	rows, errf = ReadCSVRows(strings.NewReader(""), CSVOptions{})
+	itertest.Equal(t, rows, []map[string]string{})
+	assert.NoError(t, errf())
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b strings.Builder
+	err := WriteCSV(&b, itertools.NewSeq([]string{"a", "b"}, []string{"1", "x,y"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "a,b\n1,\"x,y\"\n", b.String())
+
+	records, errf := ReadCSV(strings.NewReader(b.String()), CSVOptions{})
+	assert.Equal(t, [][]string{{"a", "b"}, {"1", "x,y"}}, slices.Collect(records))
+	assert.NoError(t, errf())
+}
@@ -0,0 +1,150 @@
+// Package iterio turns readers into sequences.
+//
+// Reading can fail part way through, so each function returns the seq along
+// with a func that reports the error that ended it, if any. Check it once the
+// loop is done:
+//
+//	lines, errf := iterio.Lines(r)
+//	for line := range lines {
+//		...
+//	}
+//	if err := errf(); err != nil {
+//		...
+//	}
+package iterio
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+)
+
+type Options struct {
+	// ReuseBuffer yields slices of one shared buffer instead of a fresh copy of
+	// each item, so an item is only valid until the loop moves on.
+	ReuseBuffer bool
+	// BufferSize is the size of the initial read buffer. For Scan and
+	// LineBytes it is also the largest token allowed, and defaults to
+	// bufio.MaxScanTokenSize.
+	BufferSize int
+}
+
+func (o Options) bufferSize(def int) int {
+	if o.BufferSize > 0 {
+		return o.BufferSize
+	}
+	return def
+}
+
+// Scan yields the tokens that split finds in r, the same way bufio.Scanner
+// does.
+func Scan(r io.Reader, split bufio.SplitFunc, opts Options) (iter.Seq[[]byte], func() error) {
+	var err error
+
+	seq := func(yield func([]byte) bool) {
+		size := opts.bufferSize(bufio.MaxScanTokenSize)
+
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 0, min(size, 4096)), size)
+		sc.Split(split)
+
+		for sc.Scan() {
+			tok := sc.Bytes()
+			if !opts.ReuseBuffer {
+				tok = bytes.Clone(tok)
+			}
+			if !yield(tok) {
+				return
+			}
+		}
+		err = sc.Err()
+	}
+
+	return seq, func() error { return err }
+}
+
+// LineBytes yields the lines of r without their line endings.
+func LineBytes(r io.Reader, opts Options) (iter.Seq[[]byte], func() error) {
+	return Scan(r, bufio.ScanLines, opts)
+}
+
+// Lines yields the lines of r without their line endings.
+func Lines(r io.Reader) (iter.Seq[string], func() error) {
+	lines, errf := LineBytes(r, Options{ReuseBuffer: true})
+
+	seq := func(yield func(string) bool) {
+		for line := range lines {
+			if !yield(string(line)) {
+				return
+			}
+		}
+	}
+
+	return seq, errf
+}
+
+// Chunks yields r in pieces of size bytes. The last piece may be shorter.
+// It panics if size isn't positive.
+func Chunks(r io.Reader, size int, opts Options) (iter.Seq[[]byte], func() error) {
+	if size <= 0 {
+		panic(fmt.Sprintf("Chunks size must be positive, not %d", size))
+	}
+
+	var err error
+
+	seq := func(yield func([]byte) bool) {
+		buf := make([]byte, size)
+		for {
+			n, readErr := io.ReadFull(r, buf)
+			if n > 0 {
+				chunk := buf[:n]
+				if !opts.ReuseBuffer {
+					chunk = bytes.Clone(chunk)
+				}
+				if !yield(chunk) {
+					return
+				}
+			}
+
+			if readErr != nil {
+				if !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+					err = readErr
+				}
+				return
+			}
+		}
+	}
+
+	return seq, func() error { return err }
+}
+
+// Runes yields the UTF-8 decoded runes of r. Invalid encodings come out as
+// unicode.ReplacementChar.
+func Runes(r io.Reader) (iter.Seq[rune], func() error) {
+	var err error
+
+	seq := func(yield func(rune) bool) {
+		rr, ok := r.(io.RuneReader)
+		if !ok {
+			rr = bufio.NewReader(r)
+		}
+
+		for {
+			c, _, readErr := rr.ReadRune()
+			if readErr != nil {
+				if !errors.Is(readErr, io.EOF) {
+					err = readErr
+				}
+				return
+			}
+			if !yield(c) {
+				return
+			}
+		}
+	}
+
+	return seq, func() error { return err }
+}
@@ -0,0 +1,135 @@
+package iterio
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/astonm/go-itertools"
+	"github.com/astonm/go-itertools/itertest"
+	"github.com/stretchr/testify/assert"
+)
+
+var errBroken = errors.New("broken")
+
+// failingReader yields s and then fails with errBroken.
+func failingReader(s string) io.Reader {
+	return io.MultiReader(strings.NewReader(s), iotest.ErrReader(errBroken))
+}
+
+func TestLines(t *testing.T) {
+	lines, errf := Lines(strings.NewReader("one\ntwo\r\n\nthree"))
+	itertest.Equal(t, lines, []string{"one", "two", "", "three"})
+	assert.NoError(t, errf())
+
+	lines, errf = Lines(strings.NewReader(""))
+	itertest.Equal(t, lines, []string{})
+	assert.NoError(t, errf())
+}
+
+func TestLinesError(t *testing.T) {
+	lines, errf := Lines(failingReader("one\ntwo\nthr"))
+	itertest.Equal(t, lines, []string{"one", "two", "thr"})
+	assert.ErrorIs(t, errf(), errBroken)
+}
+
+func TestLinesBreak(t *testing.T) {
+	lines, errf := Lines(failingReader("one\ntwo\n"))
+	itertest.Equal(t, itertools.Take(lines, 1), []string{"one"})
+	assert.NoError(t, errf())
+}
+
+func TestLinesPipeline(t *testing.T) {
+	lines, errf := Lines(strings.NewReader("a\nb\nc\nd\ne"))
+
+	var got []string
+	for i, batch := range itertools.Enumerate(itertools.Batched(itertools.Map(strings.ToUpper, lines), 2)) {
+		got = append(got, strings.Repeat("#", i+1)+strings.Join(batch, ""))
+	}
+	assert.Equal(t, []string{"#AB", "##CD", "###E"}, got)
+	assert.NoError(t, errf())
+}
+
+func TestLineBytes(t *testing.T) {
+	lines, errf := LineBytes(strings.NewReader("one\ntwo\nthree"), Options{})
+	got := slices.Collect(lines)
+	assert.Equal(t, [][]byte{[]byte("one"), []byte("two"), []byte("three")}, got)
+	assert.NoError(t, errf())
+}
+
+func TestLineBytesReuseBuffer(t *testing.T) {
+	lines, errf := LineBytes(iotest.OneByteReader(strings.NewReader("one\ntwo\nsix")), Options{ReuseBuffer: true})
+
+	var got []string
+	for line := range lines {
+		got = append(got, string(line))
+	}
+	assert.Equal(t, []string{"one", "two", "six"}, got)
+	assert.NoError(t, errf())
+
+	text := strings.Repeat("some line of text\n", 1000)
+	allocs := func(opts Options) float64 {
+		return testing.AllocsPerRun(10, func() {
+			lines, _ := LineBytes(strings.NewReader(text), opts)
+			for range lines {
+			}
+		})
+	}
+	assert.Less(t, allocs(Options{ReuseBuffer: true}), float64(20))
+	assert.GreaterOrEqual(t, allocs(Options{}), float64(1000))
+}
+
+func TestLineBytesTooLong(t *testing.T) {
+	lines, errf := LineBytes(strings.NewReader("short\nmuch too long\n"), Options{BufferSize: 8})
+	itertest.Equal(t, itertools.Map(func(b []byte) string { return string(b) }, lines), []string{"short"})
+	assert.ErrorIs(t, errf(), bufio.ErrTooLong)
+}
+
+func TestScan(t *testing.T) {
+	words, errf := Scan(strings.NewReader("the  quick\nbrown fox"), bufio.ScanWords, Options{})
+	itertest.Equal(t, itertools.Map(func(b []byte) string { return string(b) }, words), []string{"the", "quick", "brown", "fox"})
+	assert.NoError(t, errf())
+}
+
+func TestChunks(t *testing.T) {
+	chunks, errf := Chunks(strings.NewReader("abcdefgh"), 3, Options{})
+	itertest.Equal(t, chunks, [][]byte{[]byte("abc"), []byte("def"), []byte("gh")})
+	assert.NoError(t, errf())
+
+	chunks, errf = Chunks(iotest.HalfReader(strings.NewReader("abcdef")), 3, Options{ReuseBuffer: true})
+	var got []string
+	for c := range chunks {
+		got = append(got, string(c))
+	}
+	assert.Equal(t, []string{"abc", "def"}, got)
+	assert.NoError(t, errf())
+}
+
+func TestChunksError(t *testing.T) {
+	chunks, errf := Chunks(failingReader("abcd"), 3, Options{})
+	itertest.Equal(t, chunks, [][]byte{[]byte("abc"), []byte("d")})
+	assert.ErrorIs(t, errf(), errBroken)
+}
+
+func TestChunksSize(t *testing.T) {
+	assert.PanicsWithValue(t, "Chunks size must be positive, not 0", func() {
+		Chunks(strings.NewReader("abc"), 0, Options{})
+	})
+	assert.PanicsWithValue(t, "Chunks size must be positive, not -1", func() {
+		Chunks(strings.NewReader("abc"), -1, Options{})
+	})
+}
+
+func TestRunes(t *testing.T) {
+	runes, errf := Runes(iotest.OneByteReader(strings.NewReader("héllo, 世界")))
+	itertest.Equal(t, runes, []rune("héllo, 世界"))
+	assert.NoError(t, errf())
+
+	runes, errf = Runes(failingReader("ab"))
+	itertest.Equal(t, runes, []rune("ab"))
+	assert.ErrorIs(t, errf(), errBroken)
+}
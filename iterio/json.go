@@ -0,0 +1,233 @@
+package iterio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"time"
+)
+
+// ReadJSONL decodes each line of r as a T. Blank lines are skipped, and
+// errors say which line they came from.
+func ReadJSONL[T any](r io.Reader) (iter.Seq[T], func() error) {
+	var err error
+
+	seq := func(yield func(T) bool) {
+		br := bufio.NewReader(r)
+		for lineNum := 1; ; lineNum++ {
+			line, readErr := br.ReadBytes('\n')
+			if readErr != nil && !errors.Is(readErr, io.EOF) {
+				err = fmt.Errorf("line %d: %w", lineNum, readErr)
+				return
+			}
+
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				var v T
+				if decodeErr := json.Unmarshal(line, &v); decodeErr != nil {
+					err = fmt.Errorf("line %d: %w", lineNum, decodeErr)
+					return
+				}
+				if !yield(v) {
+					return
+				}
+			}
+
+			if readErr != nil {
+				return
+			}
+		}
+	}
+
+	return seq, func() error { return err }
+}
+
+// WriteJSONL writes every item of seq to w as JSON, one per line.
+func WriteJSONL[T any](w io.Writer, seq iter.Seq[T]) error {
+	enc := json.NewEncoder(w)
+	for v := range seq {
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadJSONArray decodes the elements of a top-level JSON array in r one at a
+// time, without reading the whole array into memory.
+func ReadJSONArray[T any](r io.Reader) (iter.Seq[T], func() error) {
+	var err error
+
+	seq := func(yield func(T) bool) {
+		dec := json.NewDecoder(r)
+
+		tok, tokErr := dec.Token()
+		if tokErr != nil {
+			err = tokErr
+			return
+		}
+		if tok != json.Delim('[') {
+			err = fmt.Errorf("expected a JSON array, got %v", tok)
+			return
+		}
+
+		for i := 0; dec.More(); i++ {
+			var v T
+			if decodeErr := dec.Decode(&v); decodeErr != nil {
+				err = fmt.Errorf("element %d: %w", i, decodeErr)
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+
+		if _, tokErr := dec.Token(); tokErr != nil {
+			err = tokErr
+		}
+	}
+
+	return seq, func() error { return err }
+}
+
+// JSONStream encodes a seq as JSON while ranging it, so the whole value never
+// has to be held in memory. Use JSONArray or JSONObject to make one.
+type JSONStream struct {
+	// FlushInterval is how often WriteTo flushes the writer, if it has a
+	// Flush method or is an http.Flusher. Zero flushes after every element.
+	FlushInterval time.Duration
+
+	open, close byte
+	elems       iter.Seq2[[]byte, error]
+}
+
+// JSONArray encodes the items of seq as a JSON array.
+func JSONArray[T any](seq iter.Seq[T]) *JSONStream {
+	elems := func(yield func([]byte, error) bool) {
+		for v := range seq {
+			if !yield(json.Marshal(v)) {
+				return
+			}
+		}
+	}
+	return &JSONStream{open: '[', close: ']', elems: elems}
+}
+
+// JSONObject encodes the pairs of seq as the members of a JSON object. Keys
+// are encoded the way encoding/json encodes map keys.
+func JSONObject[K comparable, V any](seq iter.Seq2[K, V]) *JSONStream {
+	elems := func(yield func([]byte, error) bool) {
+		for k, v := range seq {
+			// a one entry map gets the same key handling as a real one
+			b, err := json.Marshal(map[K]V{k: v})
+			if err == nil {
+				b = b[1 : len(b)-1]
+			}
+			if !yield(b, err) {
+				return
+			}
+		}
+	}
+	return &JSONStream{open: '{', close: '}', elems: elems}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func flusher(w io.Writer) func() error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush
+	case http.Flusher:
+		return func() error {
+			f.Flush()
+			return nil
+		}
+	}
+	return func() error { return nil }
+}
+
+// WriteTo ranges the seq and writes the encoded JSON to w.
+func (s *JSONStream) WriteTo(w io.Writer) (int64, error) {
+	return s.writeTo(w, flusher(w))
+}
+
+func (s *JSONStream) writeTo(w io.Writer, flush func() error) (int64, error) {
+	cw := &countingWriter{w: w}
+
+	if _, err := cw.Write([]byte{s.open}); err != nil {
+		return cw.n, err
+	}
+
+	lastFlush := time.Now()
+	first := true
+	for b, err := range s.elems {
+		if err != nil {
+			return cw.n, err
+		}
+
+		if !first {
+			if _, err := cw.Write([]byte{','}); err != nil {
+				return cw.n, err
+			}
+		}
+		first = false
+
+		if _, err := cw.Write(b); err != nil {
+			return cw.n, err
+		}
+
+		if time.Since(lastFlush) >= s.FlushInterval {
+			if err := flush(); err != nil {
+				return cw.n, err
+			}
+			lastFlush = time.Now()
+		}
+	}
+
+	if _, err := cw.Write([]byte{s.close}); err != nil {
+		return cw.n, err
+	}
+	return cw.n, flush()
+}
+
+// MarshalJSON ranges the seq and returns the encoded JSON, so a JSONStream
+// can be used as a field of a value passed to json.Marshal.
+func (s *JSONStream) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := s.WriteTo(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// ServeHTTP streams the JSON as the response body. If encoding fails part way
+// through, the response is aborted so the client doesn't mistake what was
+// sent for the whole value.
+func (s *JSONStream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rc := http.NewResponseController(w)
+	flush := func() error {
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	if _, err := s.writeTo(w, flush); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
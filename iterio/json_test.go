@@ -0,0 +1,200 @@
+package iterio
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/astonm/go-itertools"
+	"github.com/astonm/go-itertools/itertest"
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func TestReadJSONL(t *testing.T) {
+	points, errf := ReadJSONL[point](strings.NewReader("{\"x\":1,\"y\":2}\n\n{\"x\":3,\"y\":4}"))
+	itertest.Equal(t, points, []point{{1, 2}, {3, 4}})
+	assert.NoError(t, errf())
+}
+
+func TestReadJSONLError(t *testing.T) {
+	points, errf := ReadJSONL[point](strings.NewReader("{\"x\":1}\n{\"x\":\"two\"}\n{\"x\":3}\n"))
+	itertest.Equal(t, points, []point{{X: 1}})
+
+	var typeErr *json.UnmarshalTypeError
+	assert.ErrorAs(t, errf(), &typeErr)
+	assert.ErrorContains(t, errf(), "line 2: ")
+
+	points, errf = ReadJSONL[point](failingReader("{\"x\":1}\n"))
+	itertest.Equal(t, points, []point{{X: 1}})
+	assert.ErrorIs(t, errf(), errBroken)
+	assert.ErrorContains(t, errf(), "line 2: ")
+}
+
+func TestWriteJSONL(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, WriteJSONL(&b, itertools.NewSeq(point{1, 2}, point{3, 4})))
+	assert.Equal(t, "{\"x\":1,\"y\":2}\n{\"x\":3,\"y\":4}\n", b.String())
+
+	points, errf := ReadJSONL[point](strings.NewReader(b.String()))
+	itertest.Equal(t, points, []point{{1, 2}, {3, 4}})
+	assert.NoError(t, errf())
+
+	assert.Error(t, WriteJSONL(&b, itertools.NewSeq(func() {})))
+}
+
+func TestReadJSONArray(t *testing.T) {
+	points, errf := ReadJSONArray[point](strings.NewReader(` [{"x":1,"y":2}, {"x":3,"y":4}] `))
+	itertest.Equal(t, points, []point{{1, 2}, {3, 4}})
+	assert.NoError(t, errf())
+
+	nums, errf := ReadJSONArray[int](strings.NewReader(`[]`))
+	itertest.Equal(t, nums, []int{})
+	assert.NoError(t, errf())
+}
+
+func TestReadJSONArrayStreams(t *testing.T) {
+	// the reader fails after the first element, which still comes through
+	nums, errf := ReadJSONArray[int](failingReader("[1, 2,"))
+	itertest.Equal(t, itertools.Take(nums, 1), []int{1})
+	assert.NoError(t, errf())
+
+	nums, errf = ReadJSONArray[int](failingReader("[1, 2,"))
+	itertest.Equal(t, nums, []int{1, 2})
+	assert.ErrorIs(t, errf(), errBroken)
+}
+
+func TestReadJSONArrayError(t *testing.T) {
+	nums, errf := ReadJSONArray[int](strings.NewReader(`{"a": 1}`))
+	itertest.Equal(t, nums, []int{})
+	assert.EqualError(t, errf(), "expected a JSON array, got {")
+
+	nums, errf = ReadJSONArray[int](strings.NewReader(`[1, "two"]`))
+	itertest.Equal(t, nums, []int{1})
+	assert.ErrorContains(t, errf(), "element 1: ")
+}
+
+type flushRecorder struct {
+	strings.Builder
+	flushes []string
+}
+
+func (f *flushRecorder) Flush() error {
+	f.flushes = append(f.flushes, f.String())
+	return nil
+}
+
+func TestJSONArray(t *testing.T) {
+	var b strings.Builder
+	n, err := JSONArray(itertools.NewSeq(point{1, 2}, point{3, 4})).WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"x":1,"y":2},{"x":3,"y":4}]`, b.String())
+	assert.Equal(t, int64(b.Len()), n)
+
+	b.Reset()
+	_, err = JSONArray(itertools.NewSeq[int]()).WriteTo(&b)
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, b.String())
+}
+
+func TestJSONArrayFlush(t *testing.T) {
+	f := &flushRecorder{}
+	_, err := JSONArray(itertools.NewSeq(1, 2)).WriteTo(f)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"[1", "[1,2", "[1,2]"}, f.flushes)
+
+	f = &flushRecorder{}
+	s := JSONArray(itertools.NewSeq(1, 2, 3))
+	s.FlushInterval = time.Hour
+	_, err = s.WriteTo(f)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"[1,2,3]"}, f.flushes)
+}
+
+func TestJSONArrayMarshal(t *testing.T) {
+	b, err := json.Marshal(map[string]any{"items": JSONArray(itertools.Take(itertools.Count(), 3))})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"items": [0, 1, 2]}`, string(b))
+
+	_, err = json.Marshal(JSONArray(itertools.NewSeq(func() {})))
+	assert.Error(t, err)
+}
+
+func TestJSONObject(t *testing.T) {
+	b, err := json.Marshal(JSONObject(itertools.Zip(itertools.NewSeq("a", "b"), itertools.Count())))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":0,"b":1}`, string(b))
+
+	b, err = json.Marshal(JSONObject(itertools.Enumerate(itertools.NewSeq("x", "y"))))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"0":"x","1":"y"}`, string(b))
+
+	b, err = json.Marshal(JSONObject(itertools.Enumerate(itertools.NewSeq[string]())))
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(b))
+}
+
+func TestJSONStreamServeHTTP(t *testing.T) {
+	srv := httptest.NewServer(JSONArray(itertools.Take(itertools.Count(), 5)))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	nums, errf := ReadJSONArray[int](resp.Body)
+	itertest.Equal(t, nums, []int{0, 1, 2, 3, 4})
+	assert.NoError(t, errf())
+}
+
+func TestJSONStreamServeHTTPFlushes(t *testing.T) {
+	advance := make(chan struct{})
+	slow := func(yield func(string) bool) {
+		for _, v := range []string{"a", "b", "c"} {
+			if !yield(v) {
+				return
+			}
+			<-advance
+		}
+	}
+
+	srv := httptest.NewServer(JSONArray(slow))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	// each element arrives before the handler has produced the next one
+	var got []string
+	strs, errf := ReadJSONArray[string](resp.Body)
+	for v := range strs {
+		got = append(got, v)
+		advance <- struct{}{}
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, got)
+	assert.NoError(t, errf())
+}
+
+func TestJSONStreamServeHTTPError(t *testing.T) {
+	srv := httptest.NewServer(JSONArray(itertools.NewSeq[any](1, func() {})))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	_, err = io.ReadAll(resp.Body)
+	assert.Error(t, err)
+}
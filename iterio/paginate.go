@@ -0,0 +1,107 @@
+package iterio
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+type PaginateOptions struct {
+	// MaxPages stops after this many pages. Zero means no limit.
+	MaxPages int
+	// Prefetch fetches the next page in the background while the current one
+	// is being consumed.
+	Prefetch bool
+	// Retries is how many times a failed fetch is retried before giving up.
+	Retries int
+	// Backoff is the wait before the first retry, doubling on each one after.
+	Backoff time.Duration
+	// Retryable reports whether an error is worth retrying. If nil, every error
+	// is.
+	Retryable func(error) bool
+}
+
+// Paginate flattens the pages returned by fetch into one seq. fetch is first
+// called with the zero token, then with the token each page returns, until a
+// page returns the zero token. A page is only fetched once the consumer has
+// reached it, unless opts.Prefetch is set.
+func Paginate[T any, K comparable](ctx context.Context, fetch func(context.Context, K) ([]T, K, error), opts PaginateOptions) (iter.Seq[T], func() error) {
+	var err error
+
+	type page struct {
+		items []T
+		next  K
+		err   error
+	}
+
+	fetchWithRetry := func(ctx context.Context, token K) page {
+		backoff := opts.Backoff
+		for attempt := 0; ; attempt++ {
+			items, next, err := fetch(ctx, token)
+			if err == nil {
+				return page{items, next, nil}
+			}
+
+			retryable := opts.Retryable == nil || opts.Retryable(err)
+			if attempt == opts.Retries || !retryable || ctx.Err() != nil {
+				return page{err: err}
+			}
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return page{err: ctx.Err()}
+			}
+			backoff *= 2
+		}
+	}
+
+	seq := func(yield func(T) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+
+		var pending chan page
+		defer func() {
+			cancel()
+			if pending != nil {
+				<-pending
+			}
+		}()
+
+		var token, zero K
+		for pages := 1; ; pages++ {
+			var p page
+			if pending != nil {
+				p = <-pending
+				pending = nil
+			} else {
+				p = fetchWithRetry(ctx, token)
+			}
+
+			if p.err != nil {
+				err = p.err
+				return
+			}
+
+			more := p.next != zero && (opts.MaxPages <= 0 || pages < opts.MaxPages)
+			if more && opts.Prefetch {
+				pending = make(chan page, 1)
+				go func(pending chan<- page, token K) {
+					pending <- fetchWithRetry(ctx, token)
+				}(pending, p.next)
+			}
+
+			for _, v := range p.items {
+				if !yield(v) {
+					return
+				}
+			}
+
+			if !more {
+				return
+			}
+			token = p.next
+		}
+	}
+
+	return seq, func() error { return err }
+}
@@ -0,0 +1,179 @@
+package iterio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/astonm/go-itertools"
+	"github.com/astonm/go-itertools/itertest"
+	"github.com/stretchr/testify/assert"
+)
+
+type pageResponse struct {
+	Items []int  `json:"items"`
+	Next  string `json:"next"`
+}
+
+// pageServer serves 0..total-1 in pages of size, and fails the first
+// failures requests with a 503.
+type pageServer struct {
+	*httptest.Server
+	requests atomic.Int64
+	failures atomic.Int64
+}
+
+func newPageServer(t *testing.T, total, size int) *pageServer {
+	s := &pageServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		if s.failures.Add(-1) >= 0 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("token"))
+		resp := pageResponse{Items: []int{}}
+		for i := start; i < min(start+size, total); i++ {
+			resp.Items = append(resp.Items, i)
+		}
+		if start+size < total {
+			resp.Next = strconv.Itoa(start + size)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+type statusError int
+
+func (e statusError) Error() string { return fmt.Sprintf("status %d", int(e)) }
+
+func (s *pageServer) fetch(ctx context.Context, token string) ([]int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+"?token="+token, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", statusError(resp.StatusCode)
+	}
+
+	var page pageResponse
+	err = json.NewDecoder(resp.Body).Decode(&page)
+	return page.Items, page.Next, err
+}
+
+func TestPaginate(t *testing.T) {
+	srv := newPageServer(t, 7, 3)
+
+	items, errf := Paginate(context.Background(), srv.fetch, PaginateOptions{})
+	itertest.Equal(t, items, []int{0, 1, 2, 3, 4, 5, 6})
+	assert.NoError(t, errf())
+	assert.Equal(t, int64(3), srv.requests.Load())
+}
+
+func TestPaginateLazy(t *testing.T) {
+	srv := newPageServer(t, 100, 3)
+
+	items, errf := Paginate(context.Background(), srv.fetch, PaginateOptions{})
+	itertest.Equal(t, itertools.Take(items, 4), []int{0, 1, 2, 3})
+	assert.NoError(t, errf())
+	assert.Equal(t, int64(2), srv.requests.Load())
+}
+
+func TestPaginateMaxPages(t *testing.T) {
+	srv := newPageServer(t, 100, 3)
+
+	items, errf := Paginate(context.Background(), srv.fetch, PaginateOptions{MaxPages: 2})
+	itertest.Equal(t, items, []int{0, 1, 2, 3, 4, 5})
+	assert.NoError(t, errf())
+	assert.Equal(t, int64(2), srv.requests.Load())
+}
+
+func TestPaginatePrefetch(t *testing.T) {
+	srv := newPageServer(t, 100, 3)
+
+	itertest.AssertNoGoroutineLeak(t, func() {
+		items, errf := Paginate(context.Background(), srv.fetch, PaginateOptions{Prefetch: true})
+		for v := range items {
+			if v == 0 {
+				// the second page is fetched while the first is consumed
+				assert.Eventually(t, func() bool { return srv.requests.Load() == 2 }, time.Second, time.Millisecond)
+			}
+			if v == 4 {
+				break
+			}
+		}
+		assert.NoError(t, errf())
+		http.DefaultClient.CloseIdleConnections()
+	})
+
+	srv = newPageServer(t, 7, 3)
+	items, errf := Paginate(context.Background(), srv.fetch, PaginateOptions{Prefetch: true})
+	itertest.Equal(t, items, []int{0, 1, 2, 3, 4, 5, 6})
+	assert.NoError(t, errf())
+	assert.Equal(t, int64(3), srv.requests.Load())
+}
+
+func TestPaginateRetry(t *testing.T) {
+	srv := newPageServer(t, 5, 3)
+	srv.failures.Store(2)
+
+	items, errf := Paginate(context.Background(), srv.fetch, PaginateOptions{Retries: 2, Backoff: time.Millisecond})
+	itertest.Equal(t, items, []int{0, 1, 2, 3, 4})
+	assert.NoError(t, errf())
+	assert.Equal(t, int64(4), srv.requests.Load())
+}
+
+func TestPaginateRetryGivesUp(t *testing.T) {
+	srv := newPageServer(t, 5, 3)
+	srv.failures.Store(3)
+
+	items, errf := Paginate(context.Background(), srv.fetch, PaginateOptions{Retries: 2, Backoff: time.Millisecond})
+	itertest.Equal(t, items, []int{})
+	assert.Equal(t, statusError(http.StatusServiceUnavailable), errf())
+	assert.Equal(t, int64(3), srv.requests.Load())
+}
+
+func TestPaginateNotRetryable(t *testing.T) {
+	srv := newPageServer(t, 5, 3)
+	srv.failures.Store(1)
+
+	notRetryable := func(err error) bool {
+		var status statusError
+		return !errors.As(err, &status)
+	}
+
+	opts := PaginateOptions{Retries: 2, Backoff: time.Millisecond, Retryable: notRetryable}
+	items, errf := Paginate(context.Background(), srv.fetch, opts)
+	itertest.Equal(t, items, []int{})
+	assert.Error(t, errf())
+	assert.Equal(t, int64(1), srv.requests.Load())
+}
+
+func TestPaginateCancelled(t *testing.T) {
+	srv := newPageServer(t, 5, 3)
+	srv.failures.Store(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	items, errf := Paginate(ctx, srv.fetch, PaginateOptions{Retries: 5, Backoff: time.Hour})
+	itertest.Equal(t, items, []int{})
+	assert.ErrorIs(t, errf(), context.Canceled)
+}
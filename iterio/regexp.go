@@ -0,0 +1,130 @@
+package iterio
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"regexp"
+	"strings"
+
+	"github.com/astonm/go-itertools"
+)
+
+// ErrLeftContext is returned by ReaderMatches for a regexp that looks at the
+// text before a match.
+var ErrLeftContext = errors.New("ReaderMatches doesn't support ^, \\A, \\b or \\B")
+
+// ReaderMatches lazily yields the matches of re in r, like re.FindAllString,
+// reading only as far as each match needs. Each search after the first starts
+// where the last match ended without seeing the text before it, so a regexp
+// that uses ^, \A, \b or \B yields nothing and fails with ErrLeftContext.
+//
+// The regexp reads a little past the end of each match. If r fails there, the
+// match is dropped, since the text it couldn't read might have changed it.
+func ReaderMatches(re *regexp.Regexp, r io.RuneReader) (iter.Seq[string], func() error) {
+	var err error
+
+	seq := func(yield func(string) bool) {
+		if itertools.UsesLeftContext(re) {
+			err = ErrLeftContext
+			return
+		}
+
+		rr := &replayReader{src: r}
+		afterMatch := false
+		for {
+			loc := re.FindReaderIndex(rr)
+			if rr.err != nil {
+				err = rr.err
+				return
+			}
+			if loc == nil {
+				return
+			}
+
+			start, end := rr.index(loc[0]), rr.index(loc[1])
+			next := end
+			if end == 0 {
+				// an empty match at the start of the search; step over a
+				// rune so the next search doesn't find it again
+				if len(rr.read) == 0 {
+					if _, _, readErr := rr.ReadRune(); readErr != nil {
+						if rr.err != nil {
+							err = rr.err
+						} else if !afterMatch {
+							yield("")
+						}
+						return
+					}
+				}
+				next = 1
+			}
+
+			if end > 0 || !afterMatch {
+				if !yield(rr.text(start, end)) {
+					return
+				}
+			}
+			afterMatch = next == end
+			rr.restart(next)
+		}
+	}
+
+	return seq, func() error { return err }
+}
+
+type runeRead struct {
+	r    rune
+	size int
+}
+
+// replayReader remembers the runes read from src since the last restart, so
+// that those past the end of a match can be read again by the next search.
+type replayReader struct {
+	src     io.RuneReader
+	pending []runeRead
+	read    []runeRead
+	err     error
+}
+
+func (rr *replayReader) ReadRune() (rune, int, error) {
+	var c runeRead
+	if len(rr.pending) > 0 {
+		c, rr.pending = rr.pending[0], rr.pending[1:]
+	} else {
+		r, size, err := rr.src.ReadRune()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				rr.err = err
+			}
+			return 0, 0, err
+		}
+		c = runeRead{r, size}
+	}
+
+	rr.read = append(rr.read, c)
+	return c.r, c.size, nil
+}
+
+// index returns the number of runes read before byte offset off.
+func (rr *replayReader) index(off int) int {
+	i := 0
+	for ; off > 0; i++ {
+		off -= rr.read[i].size
+	}
+	return i
+}
+
+func (rr *replayReader) text(start, end int) string {
+	var sb strings.Builder
+	for _, c := range rr.read[start:end] {
+		sb.WriteRune(c.r)
+	}
+	return sb.String()
+}
+
+// restart makes the runes read from i on be read again.
+func (rr *replayReader) restart(i int) {
+	rr.pending = append(rr.read[i:len(rr.read):len(rr.read)], rr.pending...)
+	rr.read = nil
+}
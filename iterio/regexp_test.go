@@ -0,0 +1,104 @@
+package iterio
+
+import (
+	"bufio"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaderMatches(t *testing.T) {
+	cases := []struct{ expr, s string }{
+		{`\d+`, "a1 b22 c333 d"},
+		{`x*`, "abc"},
+		{`a*`, "baaab"},
+		{`a*?`, "baaab"},
+		{`,|`, "a,,b,"},
+		{``, "héllo"},
+		{`z`, ""},
+		{``, ""},
+		{`l+`, "héllo wörld"},
+		{`\xff`, "a\xffb"},
+	}
+
+	for _, c := range cases {
+		re := regexp.MustCompile(c.expr)
+		matches, errf := ReaderMatches(re, strings.NewReader(c.s))
+
+		var got []string
+		for m := range matches {
+			got = append(got, m)
+		}
+		assert.Equal(t, re.FindAllString(c.s, -1), got, "%q in %q", c.expr, c.s)
+		assert.NoError(t, errf())
+	}
+}
+
+func TestReaderMatchesRandom(t *testing.T) {
+	exprs := []string{`a+`, `ab*`, `a*?`, `b|`, `(a|ab)(c|bcd)`, `[ab]c?$`, `(?m)b$`, `.`, `\s*`, `x`}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 2000 {
+		b := make([]byte, rng.IntN(12))
+		for i := range b {
+			b[i] = "abcd \n"[rng.IntN(6)]
+		}
+		s := string(b)
+
+		re := regexp.MustCompile(exprs[rng.IntN(len(exprs))])
+		matches, errf := ReaderMatches(re, strings.NewReader(s))
+
+		var got []string
+		for m := range matches {
+			got = append(got, m)
+		}
+		assert.Equal(t, re.FindAllString(s, -1), got, "%q in %q", re, s)
+		assert.NoError(t, errf())
+	}
+}
+
+func TestReaderMatchesLeftContext(t *testing.T) {
+	for _, expr := range []string{`^a`, `(?m)^a`, `\Aa`, `\bx`, `a\B`, `(x|(?:\by))+`} {
+		matches, errf := ReaderMatches(regexp.MustCompile(expr), strings.NewReader("ab xy"))
+		for range matches {
+			t.Fatalf("%q yielded a match", expr)
+		}
+		assert.ErrorIs(t, errf(), ErrLeftContext, expr)
+	}
+
+	matches, errf := ReaderMatches(regexp.MustCompilePOSIX(`a+$`), strings.NewReader("baa"))
+	for m := range matches {
+		assert.Equal(t, "aa", m)
+	}
+	assert.NoError(t, errf())
+}
+
+func TestReaderMatchesLazy(t *testing.T) {
+	src := strings.NewReader(strings.Repeat("word ", 10000))
+	matches, errf := ReaderMatches(regexp.MustCompile(`\w+`), src)
+
+	n := 0
+	for range matches {
+		if n++; n == 10 {
+			break
+		}
+	}
+
+	assert.NoError(t, errf())
+	assert.Greater(t, src.Len(), 49000)
+}
+
+func TestReaderMatchesError(t *testing.T) {
+	matches, errf := ReaderMatches(regexp.MustCompile(`\d+`), bufio.NewReader(failingReader("1 22 333 4444 5")))
+
+	var got []string
+	for m := range matches {
+		got = append(got, m)
+	}
+	// the regexp reads a little past each match, so the last few are lost
+	assert.NotEmpty(t, got)
+	assert.Equal(t, []string{"1", "22", "333", "4444"}[:len(got)], got)
+	assert.ErrorIs(t, errf(), errBroken)
+}
@@ -0,0 +1,110 @@
+package iterio
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+)
+
+// Rows yields scan(rows) for each row. rows is closed once the loop ends,
+// whether it ran to the end or broke early, and the error func reports any
+// scan error, rows.Err() or error from closing.
+func Rows[T any](rows *sql.Rows, scan func(*sql.Rows) (T, error)) (iter.Seq[T], func() error) {
+	var err error
+
+	seq := func(yield func(T) bool) {
+		defer func() {
+			err = errors.Join(err, rows.Close())
+		}()
+
+		for rows.Next() {
+			v, scanErr := scan(rows)
+			if scanErr != nil {
+				err = scanErr
+				return
+			}
+			if !yield(v) {
+				return
+			}
+		}
+		err = rows.Err()
+	}
+
+	return seq, func() error { return err }
+}
+
+// StructScanner returns a scan func for Rows that fills the exported fields of
+// a struct T from the columns with the same name. A field's `db` tag
+// overrides its name, and `db:"-"` skips it. Names are matched without regard
+// to case, and every column must have a field. The scanner can be reused
+// across queries.
+func StructScanner[T any]() func(*sql.Rows) (T, error) {
+	// fields is the mapping for the columns of fieldsFor, worked out on its
+	// first row
+	var (
+		fields    []int
+		fieldsFor *sql.Rows
+	)
+
+	return func(rows *sql.Rows) (T, error) {
+		var v T
+
+		rv := reflect.ValueOf(&v).Elem()
+		if rv.Kind() != reflect.Struct {
+			return v, fmt.Errorf("StructScanner needs a struct, not %s", rv.Type())
+		}
+
+		if rows != fieldsFor {
+			cols, err := rows.Columns()
+			if err != nil {
+				return v, err
+			}
+
+			fields, err = structFields(rv.Type(), cols)
+			if err != nil {
+				return v, err
+			}
+			fieldsFor = rows
+		}
+
+		dest := make([]any, len(fields))
+		for i, f := range fields {
+			dest[i] = rv.Field(f).Addr().Interface()
+		}
+
+		return v, rows.Scan(dest...)
+	}
+}
+
+// structFields finds the index of the field of t for each column.
+func structFields(t reflect.Type, cols []string) ([]int, error) {
+	byName := make(map[string]int)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("db"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		byName[strings.ToLower(name)] = i
+	}
+
+	fields := make([]int, len(cols))
+	for i, col := range cols {
+		f, ok := byName[strings.ToLower(col)]
+		if !ok {
+			return nil, fmt.Errorf("no field of %s for column %q", t, col)
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
@@ -0,0 +1,215 @@
+package iterio
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/astonm/go-itertools"
+	"github.com/astonm/go-itertools/itertest"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDriver serves fixed tables, where the query is the table name.
+type fakeDriver struct {
+	tables map[string]fakeTable
+	open   atomic.Int64
+}
+
+type fakeTable struct {
+	columns []string
+	rows    [][]driver.Value
+	// err is returned instead of the row after the last one
+	err error
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+type fakeRows struct {
+	d     *fakeDriver
+	table fakeTable
+	next  int
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	table, ok := c.d.tables[query]
+	if !ok {
+		return nil, errors.New("no such table")
+	}
+	c.d.open.Add(1)
+	return &fakeRows{d: c.d, table: table}, nil
+}
+
+func (r *fakeRows) Columns() []string { return r.table.columns }
+
+func (r *fakeRows) Close() error {
+	r.d.open.Add(-1)
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(r.table.rows) {
+		if r.table.err != nil {
+			return r.table.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.table.rows[r.next])
+	r.next++
+	return nil
+}
+
+var errFakeRows = errors.New("connection lost")
+
+var fake = &fakeDriver{tables: map[string]fakeTable{
+	"users": {
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "ann"}, {int64(2), "bob"}, {int64(3), "cy"}},
+	},
+	"broken": {
+		columns: []string{"id", "name"},
+		rows:    [][]driver.Value{{int64(1), "ann"}},
+		err:     errFakeRows,
+	},
+	"reversed": {
+		columns: []string{"name", "id"},
+		rows:    [][]driver.Value{{"zed", int64(9)}},
+	},
+	"names": {
+		columns: []string{"first", "last"},
+		rows:    [][]driver.Value{{"ann", "lee"}},
+	},
+	"names_swapped": {
+		columns: []string{"last", "first"},
+		rows:    [][]driver.Value{{"lee", "ann"}},
+	},
+	"extra": {
+		columns: []string{"id", "name", "email"},
+		rows:    [][]driver.Value{{int64(1), "ann", "ann@example.com"}},
+	},
+}}
+
+func init() {
+	sql.Register("iterio-fake", fake)
+}
+
+func openFake(t *testing.T) *sql.DB {
+	db, err := sql.Open("iterio-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func query(t *testing.T, db *sql.DB, table string) *sql.Rows {
+	rows, err := db.Query(table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func scanName(rows *sql.Rows) (string, error) {
+	var id int
+	var name string
+	err := rows.Scan(&id, &name)
+	return name, err
+}
+
+func TestRows(t *testing.T) {
+	db := openFake(t)
+
+	names, errf := Rows(query(t, db, "users"), scanName)
+	itertest.Equal(t, names, []string{"ann", "bob", "cy"})
+	assert.NoError(t, errf())
+	assert.Zero(t, fake.open.Load())
+}
+
+func TestRowsBreak(t *testing.T) {
+	db := openFake(t)
+
+	names, errf := Rows(query(t, db, "users"), scanName)
+	itertest.Equal(t, itertools.Take(names, 1), []string{"ann"})
+	assert.NoError(t, errf())
+	assert.Zero(t, fake.open.Load())
+	assert.Zero(t, db.Stats().InUse)
+}
+
+func TestRowsErr(t *testing.T) {
+	db := openFake(t)
+
+	names, errf := Rows(query(t, db, "broken"), scanName)
+	itertest.Equal(t, names, []string{"ann"})
+	assert.ErrorIs(t, errf(), errFakeRows)
+	assert.Zero(t, fake.open.Load())
+}
+
+func TestRowsScanErr(t *testing.T) {
+	db := openFake(t)
+
+	ids, errf := Rows(query(t, db, "users"), func(rows *sql.Rows) (int, error) {
+		var id int
+		return id, rows.Scan(&id)
+	})
+	itertest.Equal(t, ids, []int{})
+	assert.ErrorContains(t, errf(), "expected 2 destination arguments")
+	assert.Zero(t, fake.open.Load())
+}
+
+type user struct {
+	ID       int
+	Name     string `db:"name"`
+	Nickname string `db:"-"`
+}
+
+func TestStructScanner(t *testing.T) {
+	db := openFake(t)
+
+	users, errf := Rows(query(t, db, "users"), StructScanner[user]())
+	itertest.Equal(t, users, []user{{ID: 1, Name: "ann"}, {ID: 2, Name: "bob"}, {ID: 3, Name: "cy"}})
+	assert.NoError(t, errf())
+
+	users, errf = Rows(query(t, db, "extra"), StructScanner[user]())
+	itertest.Equal(t, users, []user{})
+	assert.EqualError(t, errf(), `no field of iterio.user for column "email"`)
+
+	nums, errf := Rows(query(t, db, "users"), StructScanner[int]())
+	itertest.Equal(t, nums, []int{})
+	assert.EqualError(t, errf(), "StructScanner needs a struct, not int")
+	assert.Zero(t, fake.open.Load())
+}
+
+func TestStructScannerReused(t *testing.T) {
+	db := openFake(t)
+
+	scan := StructScanner[user]()
+	for _, table := range []string{"users", "reversed", "users"} {
+		users, errf := Rows(query(t, db, table), scan)
+		for range users {
+		}
+		assert.NoError(t, errf(), table)
+	}
+
+	users, errf := Rows(query(t, db, "reversed"), scan)
+	itertest.Equal(t, users, []user{{ID: 9, Name: "zed"}})
+	assert.NoError(t, errf())
+
+	type fullName struct{ First, Last string }
+	scanName := StructScanner[fullName]()
+	for _, table := range []string{"names", "names_swapped"} {
+		names, errf := Rows(query(t, db, table), scanName)
+		itertest.Equal(t, names, []fullName{{"ann", "lee"}})
+		assert.NoError(t, errf(), table)
+	}
+}
@@ -0,0 +1,150 @@
+package iterio
+
+import (
+	"io/fs"
+	"iter"
+	"os"
+	"path"
+)
+
+type WalkOptions struct {
+	// Match only yields entries whose name matches one of these path.Match
+	// patterns. Directories that don't match are still walked.
+	Match []string
+	// MaxDepth stops the walk that many levels below the root. Zero means no
+	// limit.
+	MaxDepth int
+	// FollowSymlinks walks into symlinked directories. Links that lead back to
+	// a directory being walked are not followed, for file systems such as
+	// os.DirFS whose FileInfo works with os.SameFile.
+	FollowSymlinks bool
+}
+
+// Walker walks a file tree, see WalkDir.
+type Walker struct {
+	fsys fs.FS
+	root string
+	opts WalkOptions
+
+	err  error
+	skip bool
+}
+
+// WalkDir walks the tree at root in lexical order, like fs.WalkDir, but as a
+// seq. Call SkipDir from inside the loop to skip a directory, and Err after it
+// to see why the walk stopped early.
+func WalkDir(fsys fs.FS, root string, opts WalkOptions) *Walker {
+	return &Walker{fsys: fsys, root: root, opts: opts}
+}
+
+// SkipDir skips the contents of the directory the loop is on. If the loop is
+// on a file, it skips the rest of the files in its directory.
+func (w *Walker) SkipDir() {
+	w.skip = true
+}
+
+// Err returns the error that stopped the walk, if any.
+func (w *Walker) Err() error {
+	return w.err
+}
+
+// All yields the path and entry of everything in the tree, starting with the
+// root. Breaking out of the loop stops the walk.
+func (w *Walker) All() iter.Seq2[string, fs.DirEntry] {
+	return func(yield func(string, fs.DirEntry) bool) {
+		w.err = nil
+
+		for _, pattern := range w.opts.Match {
+			if _, err := path.Match(pattern, ""); err != nil {
+				w.err = err
+				return
+			}
+		}
+
+		info, err := fs.Stat(w.fsys, w.root)
+		if err != nil {
+			w.err = err
+			return
+		}
+
+		w.visit(w.root, fs.FileInfoToDirEntry(info), 0, nil, yield)
+	}
+}
+
+type walkResult int
+
+const (
+	walkContinue walkResult = iota
+	walkSkipParent
+	walkStop
+)
+
+func (w *Walker) matches(d fs.DirEntry) bool {
+	if len(w.opts.Match) == 0 {
+		return true
+	}
+
+	for _, pattern := range w.opts.Match {
+		if ok, _ := path.Match(pattern, d.Name()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Walker) visit(name string, d fs.DirEntry, depth int, ancestors []fs.FileInfo, yield func(string, fs.DirEntry) bool) walkResult {
+	var info fs.FileInfo
+	if w.opts.FollowSymlinks && (d.IsDir() || d.Type()&fs.ModeSymlink != 0) {
+		var err error
+		info, err = fs.Stat(w.fsys, name)
+		if err != nil {
+			w.err = err
+			return walkStop
+		}
+		if info.IsDir() {
+			d = fs.FileInfoToDirEntry(info)
+		}
+	}
+
+	w.skip = false
+	if w.matches(d) && !yield(name, d) {
+		return walkStop
+	}
+
+	if w.skip {
+		w.skip = false
+		if d.IsDir() {
+			return walkContinue
+		}
+		return walkSkipParent
+	}
+
+	if !d.IsDir() || (w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth) {
+		return walkContinue
+	}
+
+	if info != nil {
+		for _, a := range ancestors {
+			if os.SameFile(a, info) {
+				return walkContinue
+			}
+		}
+		ancestors = append(ancestors, info)
+	}
+
+	entries, err := fs.ReadDir(w.fsys, name)
+	if err != nil {
+		w.err = err
+		return walkStop
+	}
+
+	for _, e := range entries {
+		switch w.visit(path.Join(name, e.Name()), e, depth+1, ancestors, yield) {
+		case walkStop:
+			return walkStop
+		case walkSkipParent:
+			return walkContinue
+		}
+	}
+	return walkContinue
+}
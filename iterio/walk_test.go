@@ -0,0 +1,163 @@
+package iterio
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFS = fstest.MapFS{
+	"a.go":           {},
+	"b.txt":          {},
+	"lib/c.go":       {},
+	"lib/d.txt":      {},
+	"lib/deep/e.go":  {},
+	"vendor/f.go":    {},
+	"vendor/g/h.go":  {},
+	"zz/empty/.keep": {},
+}
+
+func walkPaths(w *Walker) []string {
+	paths := []string{}
+	for p := range w.All() {
+		paths = append(paths, p)
+	}
+	return paths
+}
+
+func TestWalkDir(t *testing.T) {
+	w := WalkDir(testFS, ".", WalkOptions{})
+	assert.Equal(t, []string{
+		".", "a.go", "b.txt",
+		"lib", "lib/c.go", "lib/d.txt", "lib/deep", "lib/deep/e.go",
+		"vendor", "vendor/f.go", "vendor/g", "vendor/g/h.go",
+		"zz", "zz/empty", "zz/empty/.keep",
+	}, walkPaths(w))
+	assert.NoError(t, w.Err())
+
+	w = WalkDir(testFS, "lib", WalkOptions{})
+	assert.Equal(t, []string{"lib", "lib/c.go", "lib/d.txt", "lib/deep", "lib/deep/e.go"}, walkPaths(w))
+
+	var dirs []string
+	for p, d := range WalkDir(testFS, ".", WalkOptions{}).All() {
+		if d.IsDir() {
+			dirs = append(dirs, p)
+		}
+	}
+	assert.Equal(t, []string{".", "lib", "lib/deep", "vendor", "vendor/g", "zz", "zz/empty"}, dirs)
+}
+
+func TestWalkDirMatch(t *testing.T) {
+	w := WalkDir(testFS, ".", WalkOptions{Match: []string{"*.go"}})
+	assert.Equal(t, []string{"a.go", "lib/c.go", "lib/deep/e.go", "vendor/f.go", "vendor/g/h.go"}, walkPaths(w))
+
+	w = WalkDir(testFS, ".", WalkOptions{Match: []string{"*.txt", ".keep"}})
+	assert.Equal(t, []string{"b.txt", "lib/d.txt", "zz/empty/.keep"}, walkPaths(w))
+
+	w = WalkDir(testFS, ".", WalkOptions{Match: []string{"["}})
+	assert.Equal(t, []string{}, walkPaths(w))
+	assert.ErrorIs(t, w.Err(), path.ErrBadPattern)
+}
+
+func TestWalkDirMaxDepth(t *testing.T) {
+	w := WalkDir(testFS, ".", WalkOptions{MaxDepth: 1})
+	assert.Equal(t, []string{".", "a.go", "b.txt", "lib", "vendor", "zz"}, walkPaths(w))
+
+	w = WalkDir(testFS, "lib", WalkOptions{MaxDepth: 2})
+	assert.Equal(t, []string{"lib", "lib/c.go", "lib/d.txt", "lib/deep", "lib/deep/e.go"}, walkPaths(w))
+}
+
+func TestWalkDirSkipDir(t *testing.T) {
+	w := WalkDir(testFS, ".", WalkOptions{})
+
+	var paths []string
+	for p, d := range w.All() {
+		if d.IsDir() && (d.Name() == "vendor" || d.Name() == "deep") {
+			w.SkipDir()
+		}
+		if p == "a.go" {
+			w.SkipDir()
+		}
+		paths = append(paths, p)
+	}
+
+	assert.Equal(t, []string{".", "a.go"}, paths)
+
+	paths = nil
+	for p, d := range w.All() {
+		if d.IsDir() && (d.Name() == "vendor" || d.Name() == "deep") {
+			w.SkipDir()
+		}
+		paths = append(paths, p)
+	}
+	assert.Equal(t, []string{
+		".", "a.go", "b.txt",
+		"lib", "lib/c.go", "lib/d.txt", "lib/deep",
+		"vendor",
+		"zz", "zz/empty", "zz/empty/.keep",
+	}, paths)
+	assert.NoError(t, w.Err())
+}
+
+func TestWalkDirBreak(t *testing.T) {
+	var opened int
+	counting := countingFS{testFS, &opened}
+
+	w := WalkDir(counting, ".", WalkOptions{})
+	var paths []string
+	for p := range w.All() {
+		paths = append(paths, p)
+		if len(paths) == 3 {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{".", "a.go", "b.txt"}, paths)
+	// only the root directory was read
+	assert.Equal(t, 1, opened)
+}
+
+type countingFS struct {
+	fstest.MapFS
+	opened *int
+}
+
+func (c countingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	*c.opened++
+	return c.MapFS.ReadDir(name)
+}
+
+func TestWalkDirError(t *testing.T) {
+	w := WalkDir(testFS, "missing", WalkOptions{})
+	assert.Equal(t, []string{}, walkPaths(w))
+	assert.ErrorIs(t, w.Err(), fs.ErrNotExist)
+}
+
+func TestWalkDirFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"real/x.go", "other/y.go"} {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(p)), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, p), nil, 0o644))
+	}
+	assert.NoError(t, os.Symlink("../other", filepath.Join(dir, "real", "link")))
+	assert.NoError(t, os.Symlink("..", filepath.Join(dir, "real", "loop")))
+
+	fsys := os.DirFS(dir)
+
+	w := WalkDir(fsys, "real", WalkOptions{})
+	assert.Equal(t, []string{"real", "real/link", "real/loop", "real/x.go"}, walkPaths(w))
+	assert.NoError(t, w.Err())
+
+	w = WalkDir(fsys, "real", WalkOptions{FollowSymlinks: true})
+	assert.Equal(t, []string{
+		"real", "real/link", "real/link/y.go",
+		"real/loop", "real/loop/other", "real/loop/other/y.go", "real/loop/real",
+		"real/x.go",
+	}, walkPaths(w))
+	assert.NoError(t, w.Err())
+}
@@ -0,0 +1,165 @@
+// Package itertest has helpers for testing iter.Seq implementations: comparing
+// their output and checking that they follow the iterator protocol.
+package itertest
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// TB is the subset of testing.TB used by this package.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// MaxLen bounds how many items the helpers read from a seq that might be
+// infinite.
+var MaxLen = 100
+
+// take collects up to n items of s, and reports whether s had more.
+func take[T any](s iter.Seq[T], n int) (out []T, more bool) {
+	out = make([]T, 0)
+	for v := range s {
+		if len(out) == n {
+			return out, true
+		}
+		out = append(out, v)
+	}
+	return out, false
+}
+
+// diff describes how got differs from want, one line per mismatch.
+func diff[T any](got, want []T) string {
+	var b strings.Builder
+	for i := range min(len(got), len(want)) {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			fmt.Fprintf(&b, "\n\titem %d: got %#v, want %#v", i, got[i], want[i])
+		}
+	}
+
+	if len(got) > len(want) {
+		fmt.Fprintf(&b, "\n\tgot %d extra items: %#v", len(got)-len(want), got[len(want):])
+	}
+	if len(want) > len(got) {
+		fmt.Fprintf(&b, "\n\tmissing %d items: %#v", len(want)-len(got), want[len(got):])
+	}
+	return b.String()
+}
+
+// Equal checks that s yields exactly the items in want. It reads at most one
+// item past the end of want, so an infinite s fails instead of hanging.
+func Equal[T any](t TB, s iter.Seq[T], want []T) bool {
+	t.Helper()
+
+	got, _ := take(s, len(want)+1)
+	if d := diff(got, want); d != "" {
+		t.Errorf("seq does not match:%s", d)
+		return false
+	}
+	return true
+}
+
+// EqualPrefix checks that s starts with the items in want, ignoring anything
+// after them, which makes it usable with infinite seqs.
+func EqualPrefix[T any](t TB, s iter.Seq[T], want []T) bool {
+	t.Helper()
+
+	got, _ := take(s, len(want))
+	if d := diff(got, want); d != "" {
+		t.Errorf("seq prefix does not match:%s", d)
+		return false
+	}
+	return true
+}
+
+// rangeBreaking ranges s and breaks after k items, recovering any panic from
+// s calling yield after the break.
+func rangeBreaking[T any](s iter.Seq[T], k int) (p any) {
+	defer func() { p = recover() }()
+
+	var i int
+	for range s {
+		if i == k {
+			break
+		}
+		i++
+	}
+	return nil
+}
+
+// AssertStopsEarly breaks out of a loop over mk(src) after k items, for every
+// k up to the number of items it yields, and checks that the seq returns
+// cleanly and stops ranging src. src yields 0, 1, 2, ... up to MaxLen, and mk
+// may ignore it if the seq under test has no source.
+func AssertStopsEarly[T any](t TB, mk func(src iter.Seq[int]) iter.Seq[T]) bool {
+	t.Helper()
+
+	var running atomic.Int64
+	src := func(yield func(int) bool) {
+		running.Add(1)
+		defer running.Add(-1)
+
+		for i := range MaxLen {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	all, _ := take(mk(src), MaxLen)
+
+	ok := true
+	for k := range len(all) {
+		if p := rangeBreaking(mk(src), k); p != nil {
+			t.Errorf("seq panicked after breaking at item %d: %v", k, p)
+			ok = false
+		}
+		if running.Load() != 0 {
+			t.Errorf("source still running after breaking at item %d", k)
+			ok = false
+			running.Store(0)
+		}
+	}
+	return ok
+}
+
+// AssertReiterable checks that ranging s a second time yields the same items
+// as the first, comparing at most MaxLen items.
+func AssertReiterable[T any](t TB, s iter.Seq[T]) bool {
+	t.Helper()
+
+	first, _ := take(s, MaxLen)
+	second, _ := take(s, MaxLen)
+	if d := diff(second, first); d != "" {
+		t.Errorf("second iteration does not match the first:%s", d)
+		return false
+	}
+	return true
+}
+
+// AssertNoGoroutineLeak runs fn and checks that every goroutine it started,
+// including the ones behind iter.Pull, has exited shortly afterwards.
+func AssertNoGoroutineLeak(t TB, fn func()) bool {
+	t.Helper()
+
+	before := runtime.NumGoroutine()
+	fn()
+
+	var after int
+	for range 100 {
+		after = runtime.NumGoroutine()
+		if after <= before {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Errorf("leaked %d goroutines", after-before)
+	return false
+}
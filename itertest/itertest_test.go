@@ -0,0 +1,132 @@
+package itertest
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func count(yield func(int) bool) {
+	for i := 0; ; i++ {
+		if !yield(i) {
+			return
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	r := &recorder{}
+	assert.True(t, Equal(r, slices.Values([]int{1, 2, 3}), []int{1, 2, 3}))
+	assert.True(t, Equal(r, slices.Values([]int{}), []int{}))
+	assert.Empty(t, r.errors)
+
+	assert.False(t, Equal(r, slices.Values([]int{1, 5, 3, 4}), []int{1, 2, 3}))
+	assert.Equal(t, []string{"seq does not match:\n\titem 1: got 5, want 2\n\tgot 1 extra items: []int{4}"}, r.errors)
+
+	r = &recorder{}
+	assert.False(t, Equal(r, slices.Values([]string{"a"}), []string{"a", "b"}))
+	assert.Equal(t, []string{"seq does not match:\n\tmissing 1 items: []string{\"b\"}"}, r.errors)
+
+	r = &recorder{}
+	assert.False(t, Equal(r, count, []int{0, 1}))
+	assert.Equal(t, []string{"seq does not match:\n\tgot 1 extra items: []int{2}"}, r.errors)
+}
+
+func TestEqualPrefix(t *testing.T) {
+	r := &recorder{}
+	assert.True(t, EqualPrefix(r, count, []int{0, 1, 2}))
+	assert.Empty(t, r.errors)
+
+	assert.False(t, EqualPrefix(r, count, []int{0, 2}))
+	assert.Equal(t, []string{"seq prefix does not match:\n\titem 1: got 1, want 2"}, r.errors)
+
+	r = &recorder{}
+	assert.False(t, EqualPrefix(r, slices.Values([]int{0}), []int{0, 1}))
+	assert.Len(t, r.errors, 1)
+}
+
+func TestAssertStopsEarly(t *testing.T) {
+	r := &recorder{}
+	assert.True(t, AssertStopsEarly(r, func(src iter.Seq[int]) iter.Seq[int] { return src }))
+	assert.True(t, AssertStopsEarly(r, func(iter.Seq[int]) iter.Seq[int] { return count }))
+	assert.Empty(t, r.errors)
+
+	ignoresBreak := func(src iter.Seq[int]) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			for v := range src {
+				yield(v)
+			}
+		}
+	}
+	assert.False(t, AssertStopsEarly(r, ignoresBreak))
+	assert.Contains(t, r.errors[0], "seq panicked after breaking at item 0")
+
+	r = &recorder{}
+	leavesSourceRunning := func(src iter.Seq[int]) iter.Seq[int] {
+		return func(yield func(int) bool) {
+			next, _ := iter.Pull(src)
+			for {
+				v, ok := next()
+				if !ok || !yield(v) {
+					return
+				}
+			}
+		}
+	}
+	assert.False(t, AssertStopsEarly(r, leavesSourceRunning))
+	assert.Equal(t, "source still running after breaking at item 0", r.errors[0])
+}
+
+func TestAssertReiterable(t *testing.T) {
+	r := &recorder{}
+	assert.True(t, AssertReiterable(r, slices.Values([]int{1, 2})))
+	assert.True(t, AssertReiterable(r, count))
+	assert.Empty(t, r.errors)
+
+	ch := make(chan int, 2)
+	ch <- 1
+	ch <- 2
+	close(ch)
+	oneShot := func(yield func(int) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+
+	assert.False(t, AssertReiterable(r, oneShot))
+	assert.Equal(t, []string{"second iteration does not match the first:\n\tmissing 2 items: []int{1, 2}"}, r.errors)
+}
+
+func TestAssertNoGoroutineLeak(t *testing.T) {
+	r := &recorder{}
+	assert.True(t, AssertNoGoroutineLeak(r, func() {
+		next, stop := iter.Pull(count)
+		next()
+		stop()
+	}))
+	assert.Empty(t, r.errors)
+
+	var stop func()
+	assert.False(t, AssertNoGoroutineLeak(r, func() {
+		var next func() (int, bool)
+		next, stop = iter.Pull(count)
+		next()
+	}))
+	assert.Equal(t, []string{"leaked 1 goroutines"}, r.errors)
+	stop()
+}
@@ -1,6 +1,7 @@
 package itertools
 
 import (
+	"cmp"
 	"iter"
 	"slices"
 )
@@ -43,7 +44,7 @@ func Map[T any, U any](mapper func(T) U, s iter.Seq[T]) iter.Seq[U] {
 
 func Take[T any](s iter.Seq[T], n int) iter.Seq[T] {
 	return func(yield func(T) bool) {
-		next, stop := iter.Pull(s)
+		next, stop := pull(s)
 		defer stop()
 
 		for i := 0; i < n; i++ {
@@ -58,7 +59,11 @@ func Take[T any](s iter.Seq[T], n int) iter.Seq[T] {
 func Chain[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
 	return func(yield func(T) bool) {
 		for _, seq := range seqs {
-			seq(yield)
+			for v := range seq {
+				if !yield(v) {
+					return
+				}
+			}
 		}
 	}
 }
@@ -73,10 +78,24 @@ func Count() iter.Seq[int] {
 	}
 }
 
+// Cycle saves the items of s on the first pass and replays them after that,
+// so s is ranged only once.
 func Cycle[T any](s iter.Seq[T]) iter.Seq[T] {
 	return func(yield func(T) bool) {
+		var saved []T
+		for v := range s {
+			if !yield(v) {
+				return
+			}
+			saved = append(saved, v)
+		}
+
+		if len(saved) == 0 {
+			return
+		}
+
 		for {
-			for v := range s {
+			for _, v := range saved {
 				if !yield(v) {
 					return
 				}
@@ -96,6 +115,67 @@ func Repeat[T any](val T, n int) iter.Seq[T] {
 	}
 }
 
+// Iterate yields seed, f(seed), f(f(seed)) and so on.
+func Iterate[T any](seed T, f func(T) T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := seed; ; v = f(v) {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Unfold calls step on the state to get the next item and state, until step
+// reports that it is done.
+func Unfold[S any, T any](state S, step func(S) (T, S, bool)) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		s := state
+		for {
+			v, next, ok := step(s)
+			if !ok || !yield(v) {
+				return
+			}
+			s = next
+		}
+	}
+}
+
+func Generate[T any](f func() T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			if !yield(f()) {
+				return
+			}
+		}
+	}
+}
+
+// Recurrence yields initial, then keeps yielding f of the last len(initial)
+// items. The window passed to f is reused between calls.
+func Recurrence[T any](initial []T, f func(window []T) T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range initial {
+			if !yield(v) {
+				return
+			}
+		}
+
+		window := slices.Clone(initial)
+		for {
+			v := f(window)
+			if !yield(v) {
+				return
+			}
+
+			if len(window) > 0 {
+				copy(window, window[1:])
+				window[len(window)-1] = v
+			}
+		}
+	}
+}
+
 func Accumulate[T any](s iter.Seq[T], op func(T, T) T) iter.Seq[T] {
 	return func(yield func(T) bool) {
 		var sum T
@@ -123,8 +203,8 @@ func Batched[T any](s iter.Seq[T], n int) iter.Seq[[]T] {
 			batch = append(batch, v)
 		}
 
-		if len(batch) > 0 {
-			yield(batch)
+		if len(batch) > 0 && !yield(batch) {
+			return
 		}
 	}
 }
@@ -148,28 +228,94 @@ func Combinations[T any](vals []T, r int) iter.Seq[[]T] {
 			indices = append(indices, i)
 		}
 
-		yield(pick(vals, indices))
+		if !yield(pick(vals, indices)) {
+			return
+		}
 
-		for {
-			var i int
-			var found bool
-			for i = r - 1; i >= 0; i-- {
-				if indices[i] != i+len(vals)-r {
-					found = true
-					break
+		for nextCombination(indices, len(vals)) {
+			if !yield(pick(vals, indices)) {
+				return
+			}
+		}
+	}
+}
+
+// nextCombination advances indices, an increasing choice of len(indices) of n
+// items, to the next choice in lexicographic order. It reports false once
+// indices is the last choice.
+func nextCombination(indices []int, n int) bool {
+	r := len(indices)
+
+	var i int
+	for i = r - 1; i >= 0; i-- {
+		if indices[i] != i+n-r {
+			break
+		}
+	}
+
+	if i < 0 {
+		return false
+	}
+
+	indices[i]++
+	for j := i + 1; j < r; j++ {
+		indices[j] = indices[j-1] + 1
+	}
+	return true
+}
+
+func Powerset[T any](vals []T) iter.Seq[[]T] {
+	return SubsetsBetween(vals, 0, len(vals))
+}
+
+// SubsetsBetween yields every subset with between minR and maxR elements,
+// by size and then in Combinations order.
+func SubsetsBetween[T any](vals []T, minR, maxR int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		lo := max(minR, 0)
+		hi := min(maxR, len(vals))
+
+		for r := lo; r <= hi; r++ {
+			for c := range Combinations(vals, r) {
+				if !yield(c) {
+					return
 				}
 			}
+		}
+	}
+}
 
-			if !found {
-				return
+// PowersetMasks yields the subsets of n <= 64 items as bitmasks, where bit i
+// selects item i, in the same order as Powerset.
+func PowersetMasks(n int) iter.Seq[uint64] {
+	if n < 0 || n > 64 {
+		panic("itertools: PowersetMasks supports at most 64 items")
+	}
+
+	return func(yield func(uint64) bool) {
+		var indices [64]int
+
+		for r := 0; r <= n; r++ {
+			var mask uint64
+			for i := range r {
+				indices[i] = i
+				mask |= 1 << i
 			}
 
-			indices[i]++
-			for j := i + 1; j < r; j++ {
-				indices[j] = indices[j-1] + 1
+			if !yield(mask) {
+				return
 			}
 
-			yield(pick(vals, indices))
+			for nextCombination(indices[:r], n) {
+				mask = 0
+				for _, j := range indices[:r] {
+					mask |= 1 << j
+				}
+
+				if !yield(mask) {
+					return
+				}
+			}
 		}
 	}
 }
@@ -182,7 +328,9 @@ func CombinationsWithReplacement[T any](vals []T, r int) iter.Seq[[]T] {
 
 		indices := make([]int, r)
 
-		yield(pick(vals, indices))
+		if !yield(pick(vals, indices)) {
+			return
+		}
 		for {
 			var i int
 			var found bool
@@ -202,7 +350,9 @@ func CombinationsWithReplacement[T any](vals []T, r int) iter.Seq[[]T] {
 				indices[j] = nextIndex
 			}
 
-			yield(pick(vals, indices))
+			if !yield(pick(vals, indices)) {
+				return
+			}
 		}
 	}
 }
@@ -254,63 +404,38 @@ func FilterFalse[T any](pred func(T) bool, s iter.Seq[T]) iter.Seq[T] {
 
 func GroupBy[T comparable](s iter.Seq[T]) iter.Seq2[T, iter.Seq[T]] {
 	return func(yield func(T, iter.Seq[T]) bool) {
-		next, stop := iter.Pull(s)
-		defer stop()
-
-		var current T
-		var ok bool
-
-		pullGroup := func(groupValue T) iter.Seq[T] {
-			return func(yield func(T) bool) {
-				if !yield(groupValue) {
-					return
-				}
+		p := NewPeekable(s)
+		defer p.Stop()
 
-				var v T
-				for {
-					v, ok = next()
-					if !ok {
-						return
-					}
+		for {
+			key, ok := p.Peek()
+			if !ok {
+				return
+			}
 
-					if v != groupValue {
-						current = v
+			var moved bool
+			group := func(yield func(T) bool) {
+				for !moved {
+					v, ok := p.Peek()
+					if !ok || v != key {
 						return
 					}
 
+					p.Next()
 					if !yield(v) {
 						return
 					}
 				}
 			}
-		}
-
-		current, ok = next()
-		if !ok {
-			return
-		}
 
-		for {
-			group := pullGroup(current)
-			if !ok {
+			if !yield(key, group) {
 				return
 			}
 
-			if !yield(current, group) {
-				return
-			}
-
-			// exhaust remaining group items before moving to next group
-			var v T
-			for {
-				v, ok = next()
-				if !ok {
-					return
-				}
-				if v != current {
-					current = v
-					return
-				}
+			// skip remaining group items before moving to next group
+			moved = true
+			for v, ok := p.Peek(); ok && v == key; v, ok = p.Peek() {
+				p.Next()
 			}
 		}
 	}
@@ -320,7 +445,10 @@ func Slice[T any](s iter.Seq[T], start, end int) iter.Seq[T] {
 	return func(yield func(T) bool) {
 		var i int
 		for v := range s {
-			if i >= start && (end < 0 || i < end) {
+			if end >= 0 && i >= end {
+				return
+			}
+			if i >= start {
 				if !yield(v) {
 					return
 				}
@@ -332,15 +460,15 @@ func Slice[T any](s iter.Seq[T], start, end int) iter.Seq[T] {
 
 func Pairwise[T any](s iter.Seq[T]) iter.Seq2[T, T] {
 	return func(yield func(T, T) bool) {
-		next, stop := iter.Pull(s)
-		defer stop()
+		p := NewPeekable(s)
+		defer p.Stop()
 
-		a, ok := next()
+		a, ok := p.Next()
 		if !ok {
 			return
 		}
 		for {
-			b, ok := next()
+			b, ok := p.Next()
 			if !ok {
 				return
 			}
@@ -369,7 +497,9 @@ func Permutations[T any](vals []T, r int) iter.Seq[[]T] {
 			cycles = append(cycles, i)
 		}
 
-		yield(pick(vals, indices[:r]))
+		if !yield(pick(vals, indices[:r])) {
+			return
+		}
 
 		if n == 0 {
 			return
@@ -389,7 +519,9 @@ func Permutations[T any](vals []T, r int) iter.Seq[[]T] {
 					j := n - cycles[i]
 					indices[i], indices[j] = indices[j], indices[i]
 
-					yield(pick(vals, indices[:r]))
+					if !yield(pick(vals, indices[:r])) {
+						return
+					}
 					found = true
 					break
 				}
@@ -402,6 +534,109 @@ func Permutations[T any](vals []T, r int) iter.Seq[[]T] {
 	}
 }
 
+func DistinctPermutations[T cmp.Ordered](vals []T, r int) iter.Seq[[]T] {
+	return DistinctPermutationsFunc(vals, r, cmp.Compare[T])
+}
+
+// DistinctPermutationsFunc yields each distinct r-length permutation of vals
+// once, in lexicographic order under compare.
+func DistinctPermutationsFunc[T any](vals []T, r int, compare func(T, T) int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		n := len(vals)
+		if r < 0 || r > n {
+			return
+		}
+
+		perm := slices.Clone(vals)
+		slices.SortFunc(perm, compare)
+
+		for {
+			if !yield(slices.Clone(perm[:r])) {
+				return
+			}
+
+			// the tail is ascending, reversing it makes it the last arrangement
+			// of the tail so the next permutation advances the prefix
+			slices.Reverse(perm[r:])
+
+			i := n - 2
+			for i >= 0 && compare(perm[i], perm[i+1]) >= 0 {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+
+			j := n - 1
+			for compare(perm[i], perm[j]) >= 0 {
+				j--
+			}
+
+			perm[i], perm[j] = perm[j], perm[i]
+			slices.Reverse(perm[i+1:])
+		}
+	}
+}
+
+func MultisetCombinations[T cmp.Ordered](vals []T, r int) iter.Seq[[]T] {
+	return MultisetCombinationsFunc(vals, r, cmp.Compare[T])
+}
+
+// MultisetCombinationsFunc yields each distinct r-length combination of vals
+// once, in lexicographic order under compare.
+func MultisetCombinationsFunc[T any](vals []T, r int, compare func(T, T) int) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		if r < 0 || r > len(vals) {
+			return
+		}
+
+		sorted := slices.Clone(vals)
+		slices.SortFunc(sorted, compare)
+
+		var distinct []T
+		var counts []int
+		for _, v := range sorted {
+			if len(distinct) > 0 && compare(distinct[len(distinct)-1], v) == 0 {
+				counts[len(counts)-1]++
+				continue
+			}
+			distinct = append(distinct, v)
+			counts = append(counts, 1)
+		}
+
+		// remaining[i] is how many items are available from distinct[i:]
+		remaining := make([]int, len(counts)+1)
+		for i := len(counts) - 1; i >= 0; i-- {
+			remaining[i] = remaining[i+1] + counts[i]
+		}
+
+		combo := make([]T, 0, r)
+
+		var walk func(i, rem int) bool
+		walk = func(i, rem int) bool {
+			if rem == 0 {
+				return yield(slices.Clone(combo))
+			}
+			if remaining[i] < rem {
+				return true
+			}
+
+			for k := min(counts[i], rem); k >= 0; k-- {
+				for range k {
+					combo = append(combo, distinct[i])
+				}
+				if !walk(i+1, rem-k) {
+					return false
+				}
+				combo = combo[:len(combo)-k]
+			}
+			return true
+		}
+
+		walk(0, r)
+	}
+}
+
 func Product[T any](pool ...[]T) iter.Seq[[]T] {
 	return func(yield func([]T) bool) {
 		n := len(pool)
@@ -447,6 +682,257 @@ func ProductRepeat[T any](vals []T, repeat int) iter.Seq[[]T] {
 	return Product(inputs...)
 }
 
+// ProductDiagonal walks the cartesian product of seqs along diagonals of
+// increasing index sum, so every tuple is reached even when inputs are
+// infinite. Elements are buffered as they are first read.
+func ProductDiagonal[T any](seqs ...iter.Seq[T]) iter.Seq[[]T] {
+	return func(yield func([]T) bool) {
+		n := len(seqs)
+		if n == 0 {
+			yield([]T{})
+			return
+		}
+
+		nexts := make([]func() (T, bool), n)
+		for i, s := range seqs {
+			next, stop := pull(s)
+			defer stop()
+			nexts[i] = next
+		}
+
+		bufs := make([][]T, n)
+		done := make([]bool, n)
+		fill := func(i, d int) {
+			for !done[i] && len(bufs[i]) <= d {
+				v, ok := nexts[i]()
+				if !ok {
+					done[i] = true
+					return
+				}
+				bufs[i] = append(bufs[i], v)
+			}
+		}
+
+		indices := make([]int, n)
+
+		// walk yields every tuple whose indices from i onwards sum to rem
+		var walk func(i, rem int) bool
+		walk = func(i, rem int) bool {
+			if i == n-1 {
+				if rem >= len(bufs[i]) {
+					return true
+				}
+				indices[i] = rem
+
+				prod := make([]T, n)
+				for j := range n {
+					prod[j] = bufs[j][indices[j]]
+				}
+				return yield(prod)
+			}
+
+			for k := 0; k <= rem && k < len(bufs[i]); k++ {
+				indices[i] = k
+				if !walk(i+1, rem-k) {
+					return false
+				}
+			}
+			return true
+		}
+
+		for d := 0; ; d++ {
+			finite := true
+			maxSum := 0
+			for i := range n {
+				fill(i, d)
+				if len(bufs[i]) == 0 {
+					return
+				}
+				if done[i] {
+					maxSum += len(bufs[i]) - 1
+				} else {
+					finite = false
+				}
+			}
+
+			if finite && d > maxSum {
+				return
+			}
+
+			if !walk(0, d) {
+				return
+			}
+		}
+	}
+}
+
+// SetPartitions yields every way to split vals into non-empty blocks, walking
+// restricted growth strings in lexicographic order.
+func SetPartitions[T any](vals []T) iter.Seq[[][]T] {
+	return func(yield func([][]T) bool) {
+		n := len(vals)
+
+		// block[i] is the block holding vals[i], and each block is at most one
+		// more than the largest block before it
+		block := make([]int, n)
+		for {
+			var numBlocks int
+			for _, b := range block {
+				numBlocks = max(numBlocks, b+1)
+			}
+
+			part := make([][]T, numBlocks)
+			for i, b := range block {
+				part[b] = append(part[b], vals[i])
+			}
+
+			if !yield(part) {
+				return
+			}
+
+			var i int
+			for i = n - 1; i > 0; i-- {
+				if block[i] <= slices.Max(block[:i]) {
+					break
+				}
+			}
+
+			if i <= 0 {
+				return
+			}
+
+			block[i]++
+			for j := i + 1; j < n; j++ {
+				block[j] = 0
+			}
+		}
+	}
+}
+
+// IntegerPartitions yields the partitions of n as non-increasing parts, from
+// [n] down to all ones.
+func IntegerPartitions(n int) iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		if n < 0 {
+			return
+		}
+
+		parts := make([]int, 0, n)
+		if n > 0 {
+			parts = append(parts, n)
+		}
+
+		for {
+			if !yield(slices.Clone(parts)) {
+				return
+			}
+
+			var rem int
+			for len(parts) > 0 && parts[len(parts)-1] == 1 {
+				parts = parts[:len(parts)-1]
+				rem++
+			}
+
+			if len(parts) == 0 {
+				return
+			}
+
+			parts[len(parts)-1]--
+			rem++
+
+			v := parts[len(parts)-1]
+			for rem > v {
+				parts = append(parts, v)
+				rem -= v
+			}
+			parts = append(parts, rem)
+		}
+	}
+}
+
+// Compositions yields the ordered ways to write n as k positive parts, in
+// lexicographic order.
+func Compositions(n, k int) iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		if k <= 0 {
+			if n == 0 && k == 0 {
+				yield([]int{})
+			}
+			return
+		}
+
+		if n < k {
+			return
+		}
+
+		cuts := make([]int, 0, n)
+		for i := 1; i < n; i++ {
+			cuts = append(cuts, i)
+		}
+
+		for c := range Combinations(cuts, k-1) {
+			parts := make([]int, k)
+			prev := 0
+			for i, cut := range c {
+				parts[i] = cut - prev
+				prev = cut
+			}
+			parts[k-1] = n - prev
+
+			if !yield(parts) {
+				return
+			}
+		}
+	}
+}
+
+func BellNumber(n int) int {
+	row := []int{1}
+	for range n {
+		next := make([]int, 0, len(row)+1)
+		next = append(next, row[len(row)-1])
+		for _, v := range row {
+			next = append(next, next[len(next)-1]+v)
+		}
+		row = next
+	}
+	return row[0]
+}
+
+func PartitionCount(n int) int {
+	if n < 0 {
+		return 0
+	}
+
+	counts := make([]int, n+1)
+	counts[0] = 1
+	for part := 1; part <= n; part++ {
+		for i := part; i <= n; i++ {
+			counts[i] += counts[i-part]
+		}
+	}
+	return counts[n]
+}
+
+func CompositionCount(n, k int) int {
+	if k <= 0 {
+		if n == 0 && k == 0 {
+			return 1
+		}
+		return 0
+	}
+	if n < k {
+		return 0
+	}
+
+	// binomial(n-1, k-1)
+	count := 1
+	for i := 1; i < k; i++ {
+		count = count * (n - i) / i
+	}
+	return count
+}
+
 func TakeWhile[T any](pred func(T) bool, s iter.Seq[T]) iter.Seq[T] {
 	return func(yield func(T) bool) {
 		for v := range s {
@@ -457,17 +943,76 @@ func TakeWhile[T any](pred func(T) bool, s iter.Seq[T]) iter.Seq[T] {
 	}
 }
 
-func Tee[T any](s iter.Seq[T], n int) (res []iter.Seq[T]) {
-	for _ = range n {
-		res = append(res, s)
+// Tee splits s into n independent seqs. s is ranged only once, and items are
+// buffered until every copy has seen them. A copy that stops early carries on
+// from where it left off the next time it's ranged. s is released once it runs
+// out, or when stop is called; after that, copies yield only what they have
+// buffered. The copies must not be ranged from different goroutines at the
+// same time.
+func Tee[T any](s iter.Seq[T], n int) ([]iter.Seq[T], func()) {
+	var (
+		buf        []T              // items pulled from s that some copy hasn't seen
+		base       int              // index in s of buf[0]
+		pos        = make([]int, n) // index in s of each copy's next item
+		next       func() (T, bool)
+		stopSource func()
+		done       bool
+	)
+
+	stop := func() {
+		done = true
+		if stopSource != nil {
+			stopSource()
+		}
+	}
+
+	// fill pulls the next item of s into buf, reporting whether there was one
+	fill := func() bool {
+		if done {
+			return false
+		}
+		if next == nil {
+			next, stopSource = pull(s)
+		}
+
+		v, ok := next()
+		if !ok {
+			stop()
+			return false
+		}
+		buf = append(buf, v)
+		return true
+	}
+
+	res := make([]iter.Seq[T], n)
+	for i := range n {
+		res[i] = func(yield func(T) bool) {
+			for {
+				if pos[i] == base+len(buf) && !fill() {
+					return
+				}
+
+				v := buf[pos[i]-base]
+				pos[i]++
+				if seen := slices.Min(pos); seen > base {
+					clear(buf[:seen-base])
+					buf = buf[seen-base:]
+					base = seen
+				}
+
+				if !yield(v) {
+					return
+				}
+			}
+		}
 	}
-	return res
+	return res, stop
 }
 
 func Zip[T any, U any](s0 iter.Seq[T], s1 iter.Seq[U]) iter.Seq2[T, U] {
 	return func(yield func(T, U) bool) {
-		next0, stop0 := iter.Pull(s0)
-		next1, stop1 := iter.Pull(s1)
+		next0, stop0 := pull(s0)
+		next1, stop1 := pull(s1)
 
 		defer stop0()
 		defer stop1()
@@ -488,9 +1033,9 @@ func Zip[T any, U any](s0 iter.Seq[T], s1 iter.Seq[U]) iter.Seq2[T, U] {
 }
 
 func PullZip3[T any, U any, V any](s0 iter.Seq[T], s1 iter.Seq[U], s2 iter.Seq[V]) (func() (T, U, V, bool), func()) {
-	next0, stop0 := iter.Pull(s0)
-	next1, stop1 := iter.Pull(s1)
-	next2, stop2 := iter.Pull(s2)
+	next0, stop0 := pull(s0)
+	next1, stop1 := pull(s1)
+	next2, stop2 := pull(s2)
 
 	next := func() (t T, u U, v V, ok bool) {
 		v0, ok0 := next0()
@@ -510,10 +1055,10 @@ func PullZip3[T any, U any, V any](s0 iter.Seq[T], s1 iter.Seq[U], s2 iter.Seq[V
 }
 
 func PullZip4[T any, U any, V any, W any](s0 iter.Seq[T], s1 iter.Seq[U], s2 iter.Seq[V], s3 iter.Seq[W]) (func() (T, U, V, W, bool), func()) {
-	next0, stop0 := iter.Pull(s0)
-	next1, stop1 := iter.Pull(s1)
-	next2, stop2 := iter.Pull(s2)
-	next3, stop3 := iter.Pull(s3)
+	next0, stop0 := pull(s0)
+	next1, stop1 := pull(s1)
+	next2, stop2 := pull(s2)
+	next3, stop3 := pull(s3)
 
 	next := func() (t T, u U, v V, w W, ok bool) {
 		v0, ok0 := next0()
@@ -2,14 +2,17 @@ package itertools
 
 import (
 	"iter"
+	"slices"
 	"testing"
 
+	"github.com/astonm/go-itertools/itertest"
 	"github.com/stretchr/testify/assert"
 )
 
 func toSlice[T any](s iter.Seq[T]) []T {
 	maxLen := 20
-	next, _ := iter.Pull(s)
+	next, stop := iter.Pull(s)
+	defer stop()
 	out := make([]T, 0)
 	for {
 		if len(out) > maxLen {
@@ -26,8 +29,8 @@ func toSlice[T any](s iter.Seq[T]) []T {
 }
 
 func assertSequenceMatch[V any](t *testing.T, gotSeq iter.Seq[V], want []V) {
-	got := toSlice(gotSeq)
-	assert.Equal(t, want, got)
+	t.Helper()
+	itertest.Equal(t, gotSeq, want)
 }
 
 func TestNewSeq(t *testing.T) {
@@ -62,12 +65,61 @@ func TestCount(t *testing.T) {
 
 func TestCycle(t *testing.T) {
 	assertSequenceMatch(t, Take(Cycle(NewSeq(1, 2, 3)), 5), []int{1, 2, 3, 1, 2})
+	assertSequenceMatch(t, Take(Cycle(Once(NewSeq(1, 2))), 5), []int{1, 2, 1, 2, 1})
+	assertSequenceMatch(t, Cycle(NewSeq[int]()), []int{})
 }
 
 func TestRepeat(t *testing.T) {
 	assertSequenceMatch(t, Repeat("a", 5), []string{"a", "a", "a", "a", "a"})
 }
 
+func TestIterate(t *testing.T) {
+	double := func(x int) int { return x * 2 }
+	assertSequenceMatch(t, Take(Iterate(1, double), 5), []int{1, 2, 4, 8, 16})
+	assertSequenceMatch(t,
+		TakeWhile(func(x int) bool { return x < 20 }, Iterate(1, double)),
+		[]int{1, 2, 4, 8, 16},
+	)
+}
+
+func TestUnfold(t *testing.T) {
+	digits := Unfold(1234, func(n int) (int, int, bool) {
+		return n % 10, n / 10, n > 0
+	})
+	assertSequenceMatch(t, digits, []int{4, 3, 2, 1})
+
+	collatz := Unfold(6, func(n int) (int, int, bool) {
+		if n%2 == 0 {
+			return n, n / 2, n > 0
+		}
+		return n, 3*n + 1, n != 1
+	})
+	assertSequenceMatch(t, collatz, []int{6, 3, 10, 5, 16, 8, 4, 2})
+}
+
+func TestGenerate(t *testing.T) {
+	var n int
+	next := func() int {
+		n++
+		return n * n
+	}
+	assertSequenceMatch(t, Take(Generate(next), 4), []int{1, 4, 9, 16})
+}
+
+func TestRecurrence(t *testing.T) {
+	fib := Recurrence([]int{0, 1}, func(w []int) int { return w[0] + w[1] })
+	assertSequenceMatch(t, Take(fib, 10), []int{0, 1, 1, 2, 3, 5, 8, 13, 21, 34})
+	assertSequenceMatch(t, Take(fib, 1), []int{0})
+
+	tribonacci := Recurrence([]int{0, 0, 1}, func(w []int) int { return w[0] + w[1] + w[2] })
+	assertSequenceMatch(t,
+		TakeWhile(func(x int) bool { return x < 50 }, tribonacci),
+		[]int{0, 0, 1, 1, 2, 4, 7, 13, 24, 44},
+	)
+
+	assertSequenceMatch(t, Take(Recurrence(nil, func([]int) int { return 7 }), 2), []int{7, 7})
+}
+
 func TestAccumulate(t *testing.T) {
 	runningSums := Accumulate(NewSeq(1, 2, 3), func(x, y int) int { return x + y })
 	assertSequenceMatch(t, runningSums, []int{1, 3, 6})
@@ -91,6 +143,52 @@ func TestCombinations(t *testing.T) {
 	)
 }
 
+func TestPowerset(t *testing.T) {
+	assertSequenceMatch(t,
+		Powerset([]string{"A", "B", "C"}),
+		[][]string{{}, {"A"}, {"B"}, {"C"}, {"A", "B"}, {"A", "C"}, {"B", "C"}, {"A", "B", "C"}},
+	)
+
+	assertSequenceMatch(t, Powerset([]int{}), [][]int{{}})
+	assertSequenceMatch(t, Take(Powerset([]int{1, 2}), 1), [][]int{{}})
+}
+
+func TestSubsetsBetween(t *testing.T) {
+	assertSequenceMatch(t,
+		SubsetsBetween([]string{"A", "B", "C", "D"}, 2, 3),
+		[][]string{
+			{"A", "B"}, {"A", "C"}, {"A", "D"}, {"B", "C"}, {"B", "D"}, {"C", "D"},
+			{"A", "B", "C"}, {"A", "B", "D"}, {"A", "C", "D"}, {"B", "C", "D"},
+		},
+	)
+
+	assertSequenceMatch(t, SubsetsBetween([]int{1, 2}, -1, 5), [][]int{{}, {1}, {2}, {1, 2}})
+	assertSequenceMatch(t, SubsetsBetween([]int{1, 2}, 2, 1), [][]int{})
+}
+
+func TestPowersetMasks(t *testing.T) {
+	assertSequenceMatch(t,
+		PowersetMasks(3),
+		[]uint64{0b000, 0b001, 0b010, 0b100, 0b011, 0b101, 0b110, 0b111},
+	)
+
+	vals := []byte("ABCD")
+	var fromMasks [][]byte
+	for mask := range PowersetMasks(len(vals)) {
+		subset := []byte{}
+		for i, v := range vals {
+			if mask&(1<<i) != 0 {
+				subset = append(subset, v)
+			}
+		}
+		fromMasks = append(fromMasks, subset)
+	}
+	assert.Equal(t, toSlice(Powerset(vals)), fromMasks)
+
+	assertSequenceMatch(t, Take(PowersetMasks(64), 3), []uint64{0, 1, 2})
+	assert.Panics(t, func() { PowersetMasks(65) })
+}
+
 func TestCombinationsWithReplacement(t *testing.T) {
 	assertSequenceMatch(t,
 		CombinationsWithReplacement([]string{"A", "B", "C"}, 2),
@@ -132,9 +230,32 @@ func TestGroupBy(t *testing.T) {
 		"D": []string{"D"},
 	}
 
+	var keys []string
 	for k, g := range GroupBy(NewSeq("A", "A", "A", "A", "B", "B", "B", "C", "C", "D")) {
+		keys = append(keys, k)
 		assertSequenceMatch(t, g, want[k])
 	}
+	assert.Equal(t, []string{"A", "B", "C", "D"}, keys)
+}
+
+func TestGroupByPartiallyConsumed(t *testing.T) {
+	var keys []int
+	var groups [][]int
+	for k, g := range GroupBy(NewSeq(1, 1, 1, 2, 2, 1, 3)) {
+		keys = append(keys, k)
+		groups = append(groups, toSlice(Take(g, 2)))
+	}
+
+	assert.Equal(t, []int{1, 2, 1, 3}, keys)
+	assert.Equal(t, [][]int{{1, 1}, {2, 2}, {1}, {3}}, groups)
+
+	var stale []iter.Seq[int]
+	for _, g := range GroupBy(NewSeq(1, 2, 1)) {
+		stale = append(stale, g)
+	}
+	for _, g := range stale {
+		assertSequenceMatch(t, g, []int{})
+	}
 }
 
 func TestSlice(t *testing.T) {
@@ -147,6 +268,8 @@ func TestSlice(t *testing.T) {
 		Slice(NewSeq([]byte("ABCDEFG")...), 2, -1),
 		[]byte("CDEFG"),
 	)
+
+	assertSequenceMatch(t, Slice(Count(), 3, 5), []int{3, 4})
 }
 
 func TestPairwise(t *testing.T) {
@@ -192,6 +315,62 @@ func TestPermutations(t *testing.T) {
 	)
 }
 
+func TestDistinctPermutations(t *testing.T) {
+	assertSequenceMatch(t,
+		DistinctPermutations([]string{"B", "A", "A"}, 3),
+		[][]string{{"A", "A", "B"}, {"A", "B", "A"}, {"B", "A", "A"}},
+	)
+
+	assertSequenceMatch(t,
+		DistinctPermutations([]int{1, 1, 2, 2}, 2),
+		[][]int{{1, 1}, {1, 2}, {2, 1}, {2, 2}},
+	)
+
+	assertSequenceMatch(t,
+		DistinctPermutations([]int{2, 0, 1}, 2),
+		toSlice(Permutations([]int{0, 1, 2}, 2)),
+	)
+
+	assertSequenceMatch(t, DistinctPermutations([]int{1, 2}, 0), [][]int{{}})
+	assertSequenceMatch(t, DistinctPermutations([]int{1, 2}, 3), [][]int{})
+}
+
+func TestDistinctPermutationsFunc(t *testing.T) {
+	desc := func(a, b int) int { return b - a }
+	assertSequenceMatch(t,
+		DistinctPermutationsFunc([]int{1, 2, 1}, 3, desc),
+		[][]int{{2, 1, 1}, {1, 2, 1}, {1, 1, 2}},
+	)
+}
+
+func TestMultisetCombinations(t *testing.T) {
+	assertSequenceMatch(t,
+		MultisetCombinations([]string{"B", "A", "A", "C"}, 2),
+		[][]string{{"A", "A"}, {"A", "B"}, {"A", "C"}, {"B", "C"}},
+	)
+
+	assertSequenceMatch(t,
+		MultisetCombinations([]int{1, 1, 1, 2, 2}, 3),
+		[][]int{{1, 1, 1}, {1, 1, 2}, {1, 2, 2}},
+	)
+
+	assertSequenceMatch(t,
+		MultisetCombinations([]int{3, 1, 2, 0}, 3),
+		toSlice(Combinations([]int{0, 1, 2, 3}, 3)),
+	)
+
+	assertSequenceMatch(t, MultisetCombinations([]int{1, 1}, 0), [][]int{{}})
+	assertSequenceMatch(t, MultisetCombinations([]int{1, 1}, 3), [][]int{})
+}
+
+func TestMultisetCombinationsFunc(t *testing.T) {
+	desc := func(a, b int) int { return b - a }
+	assertSequenceMatch(t,
+		MultisetCombinationsFunc([]int{1, 2, 1, 3}, 2, desc),
+		[][]int{{3, 2}, {3, 1}, {2, 1}, {1, 1}},
+	)
+}
+
 func TestProduct(t *testing.T) {
 	assertSequenceMatch(t,
 		Product([]byte("ABCD"), []byte("xy")),
@@ -224,6 +403,129 @@ func TestProductRepeat(t *testing.T) {
 	)
 }
 
+func TestProductDiagonal(t *testing.T) {
+	assertSequenceMatch(t,
+		Take(ProductDiagonal(Count(), Count()), 10),
+		[][]int{
+			{0, 0},
+			{0, 1},
+			{1, 0},
+			{0, 2},
+			{1, 1},
+			{2, 0},
+			{0, 3},
+			{1, 2},
+			{2, 1},
+			{3, 0},
+		},
+	)
+
+	assertSequenceMatch(t,
+		ProductDiagonal(NewSeq([]byte("AB")...), NewSeq([]byte("xyz")...)),
+		[][]byte{
+			{'A', 'x'},
+			{'A', 'y'},
+			{'B', 'x'},
+			{'A', 'z'},
+			{'B', 'y'},
+			{'B', 'z'},
+		},
+	)
+
+	assertSequenceMatch(t,
+		Take(ProductDiagonal(NewSeq(0, 1), Count(), NewSeq(7)), 5),
+		[][]int{
+			{0, 0, 7},
+			{0, 1, 7},
+			{1, 0, 7},
+			{0, 2, 7},
+			{1, 1, 7},
+		},
+	)
+
+	assertSequenceMatch(t, ProductDiagonal(NewSeq(1, 2), NewSeq[int]()), [][]int{})
+}
+
+func countSeq[T any](s iter.Seq[T]) int {
+	var n int
+	for range s {
+		n++
+	}
+	return n
+}
+
+func TestSetPartitions(t *testing.T) {
+	assertSequenceMatch(t,
+		SetPartitions([]string{"A", "B", "C"}),
+		[][][]string{
+			{{"A", "B", "C"}},
+			{{"A", "B"}, {"C"}},
+			{{"A", "C"}, {"B"}},
+			{{"A"}, {"B", "C"}},
+			{{"A"}, {"B"}, {"C"}},
+		},
+	)
+
+	assertSequenceMatch(t, SetPartitions([]int{}), [][][]int{{}})
+
+	for n := range 8 {
+		assert.Equal(t, BellNumber(n), countSeq(SetPartitions(make([]int, n))))
+	}
+}
+
+func TestIntegerPartitions(t *testing.T) {
+	assertSequenceMatch(t,
+		IntegerPartitions(5),
+		[][]int{{5}, {4, 1}, {3, 2}, {3, 1, 1}, {2, 2, 1}, {2, 1, 1, 1}, {1, 1, 1, 1, 1}},
+	)
+
+	assertSequenceMatch(t, IntegerPartitions(0), [][]int{{}})
+	assertSequenceMatch(t, IntegerPartitions(-1), [][]int{})
+
+	for n := range 15 {
+		assert.Equal(t, PartitionCount(n), countSeq(IntegerPartitions(n)))
+	}
+}
+
+func TestCompositions(t *testing.T) {
+	assertSequenceMatch(t,
+		Compositions(5, 3),
+		[][]int{{1, 1, 3}, {1, 2, 2}, {1, 3, 1}, {2, 1, 2}, {2, 2, 1}, {3, 1, 1}},
+	)
+
+	assertSequenceMatch(t, Compositions(3, 1), [][]int{{3}})
+	assertSequenceMatch(t, Compositions(0, 0), [][]int{{}})
+	assertSequenceMatch(t, Compositions(2, 3), [][]int{})
+
+	for n := range 8 {
+		for k := range n + 2 {
+			assert.Equal(t, CompositionCount(n, k), countSeq(Compositions(n, k)))
+		}
+	}
+}
+
+func TestBellNumber(t *testing.T) {
+	want := []int{1, 1, 2, 5, 15, 52, 203, 877, 4140}
+	for n, b := range want {
+		assert.Equal(t, b, BellNumber(n))
+	}
+}
+
+func TestPartitionCount(t *testing.T) {
+	want := []int{1, 1, 2, 3, 5, 7, 11, 15, 22, 30, 42}
+	for n, p := range want {
+		assert.Equal(t, p, PartitionCount(n))
+	}
+	assert.Equal(t, 190569292, PartitionCount(100))
+}
+
+func TestCompositionCount(t *testing.T) {
+	assert.Equal(t, 6, CompositionCount(5, 3))
+	assert.Equal(t, 1, CompositionCount(0, 0))
+	assert.Equal(t, 0, CompositionCount(2, 3))
+	assert.Equal(t, 0, CompositionCount(3, 0))
+}
+
 func TestMap(t *testing.T) {
 	assertSequenceMatch(t,
 		Map(func(x int) byte { return byte('0' + x) }, NewSeq(0, 1, 2)),
@@ -240,7 +542,8 @@ func TestTakeWhile(t *testing.T) {
 
 func TestTee(t *testing.T) {
 	vals := []int{2, 4, 6, 8}
-	seqs := Tee(OfSlice(vals), 3)
+	seqs, stop := Tee(OfSlice(vals), 3)
+	defer stop()
 
 	s0, stop0 := iter.Pull(seqs[0])
 	s1, stop1 := iter.Pull(seqs[1])
@@ -268,6 +571,73 @@ func TestTee(t *testing.T) {
 	}
 }
 
+func TestTeeResume(t *testing.T) {
+	seqs, stop := Tee(Count(), 2)
+	defer stop()
+
+	assertSequenceMatch(t, Take(seqs[0], 3), []int{0, 1, 2})
+	assertSequenceMatch(t, Take(seqs[0], 3), []int{3, 4, 5})
+	assertSequenceMatch(t, Take(seqs[1], 4), []int{0, 1, 2, 3})
+	assertSequenceMatch(t, Take(seqs[1], 4), []int{4, 5, 6, 7})
+	assertSequenceMatch(t, Take(seqs[0], 3), []int{6, 7, 8})
+}
+
+func TestTeeStop(t *testing.T) {
+	running := 0
+	src := func(yield func(int) bool) {
+		running++
+		defer func() { running-- }()
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	// the second copy is never ranged, so s is held until stop
+	seqs, stop := Tee(src, 2)
+	assertSequenceMatch(t, Take(seqs[0], 3), []int{0, 1, 2})
+	assert.Equal(t, 1, running)
+
+	stop()
+	assert.Equal(t, 0, running)
+	assertSequenceMatch(t, seqs[1], []int{0, 1, 2})
+	assertSequenceMatch(t, seqs[0], []int{})
+
+	// running out of items releases s without stop
+	seqs, _ = Tee(Take(src, 3), 2)
+	assertSequenceMatch(t, seqs[0], []int{0, 1, 2})
+	assert.Equal(t, 0, running)
+	assertSequenceMatch(t, seqs[1], []int{0, 1, 2})
+}
+
+func TestTeeOneShot(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+
+	seqs, stop := Tee(FromChan(ch), 2)
+	defer stop()
+
+	assertSequenceMatch(t, Take(seqs[0], 2), []int{1, 2})
+	assertSequenceMatch(t, seqs[1], []int{1, 2, 3})
+	assertSequenceMatch(t, seqs[0], []int{3})
+
+	ch = make(chan int, 10)
+	for i := range 10 {
+		ch <- i
+	}
+	close(ch)
+
+	seqs, stop = Tee(FromChan(ch), 2)
+	defer stop()
+
+	assertSequenceMatch(t, Take(seqs[0], 3), []int{0, 1, 2})
+	assertSequenceMatch(t, seqs[1], slices.Collect(Take(Count(), 10)))
+}
+
 func TestZip(t *testing.T) {
 	chrs := OfSlice([]byte("2468"))
 	nums := OfSlice([]int{2, 4, 6, 8})
@@ -0,0 +1,67 @@
+package itertools
+
+import (
+	"iter"
+	"sync/atomic"
+)
+
+// Memoize caches the items of s as they are first produced. Later iterations
+// replay the cache and then carry on pulling s from where the furthest one
+// stopped, so s is only ever ranged once. stop releases s early; after that,
+// iterations yield only what was cached.
+func Memoize[T any](s iter.Seq[T]) (iter.Seq[T], func()) {
+	var next func() (T, bool)
+	var stopSource func()
+	var done bool
+	var cache []T
+
+	stop := func() {
+		done = true
+		if stopSource != nil {
+			stopSource()
+		}
+	}
+
+	seq := func(yield func(T) bool) {
+		for i := 0; ; i++ {
+			if i == len(cache) {
+				if done {
+					return
+				}
+				if next == nil {
+					next, stopSource = pull(s)
+				}
+
+				v, ok := next()
+				if !ok {
+					stop()
+					return
+				}
+				cache = append(cache, v)
+			}
+
+			if !yield(cache[i]) {
+				return
+			}
+		}
+	}
+
+	return seq, stop
+}
+
+// Once wraps s so that ranging it a second time panics, which catches code
+// that re-ranges a one-shot source by mistake.
+func Once[T any](s iter.Seq[T]) iter.Seq[T] {
+	var ranged atomic.Bool
+	return func(yield func(T) bool) {
+		if ranged.Swap(true) {
+			panic("itertools: Once seq ranged more than once")
+		}
+
+		for v := range s {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
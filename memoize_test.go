@@ -0,0 +1,50 @@
+package itertools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoize(t *testing.T) {
+	var produced int
+	src := Map(func(x int) int {
+		produced++
+		return x
+	}, Count())
+
+	s, stop := Memoize(src)
+	defer stop()
+
+	assertSequenceMatch(t, Take(s, 3), []int{0, 1, 2})
+	assertSequenceMatch(t, Take(s, 5), []int{0, 1, 2, 3, 4})
+	assertSequenceMatch(t, Take(s, 2), []int{0, 1})
+	assert.Equal(t, 5, produced)
+}
+
+func TestMemoizeOneShot(t *testing.T) {
+	s, stop := Memoize(Once(NewSeq(1, 2, 3)))
+	defer stop()
+
+	assertSequenceMatch(t, Take(s, 1), []int{1})
+	assertSequenceMatch(t, s, []int{1, 2, 3})
+	assertSequenceMatch(t, s, []int{1, 2, 3})
+}
+
+func TestMemoizeStop(t *testing.T) {
+	s, stop := Memoize(Count())
+
+	assertSequenceMatch(t, Take(s, 2), []int{0, 1})
+	stop()
+	assertSequenceMatch(t, s, []int{0, 1})
+	assert.NoError(t, UnstoppedPulls())
+}
+
+func TestOnce(t *testing.T) {
+	s := Once(NewSeq(1, 2))
+	assertSequenceMatch(t, s, []int{1, 2})
+	assert.PanicsWithValue(t, "itertools: Once seq ranged more than once", func() {
+		for range s {
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package itertools
+
+import (
+	"iter"
+	"slices"
+)
+
+// Peekable is a pull iterator over a seq that can look ahead and push items
+// back. Items pushed back or peeked at are buffered in front of the source.
+type Peekable[T any] struct {
+	next func() (T, bool)
+	stop func()
+	done bool
+	buf  []T
+}
+
+func NewPeekable[T any](s iter.Seq[T]) *Peekable[T] {
+	next, stop := pull(s)
+	return &Peekable[T]{next: next, stop: stop}
+}
+
+// fill buffers items from the source until there are at least n, and reports
+// whether it got there.
+func (p *Peekable[T]) fill(n int) bool {
+	for len(p.buf) < n && !p.done {
+		v, ok := p.next()
+		if !ok {
+			p.done = true
+			break
+		}
+		p.buf = append(p.buf, v)
+	}
+	return len(p.buf) >= n
+}
+
+func (p *Peekable[T]) Next() (T, bool) {
+	if !p.fill(1) {
+		var zero T
+		return zero, false
+	}
+
+	v := p.buf[0]
+	p.buf = p.buf[1:]
+	return v, true
+}
+
+func (p *Peekable[T]) Peek() (T, bool) {
+	if !p.fill(1) {
+		var zero T
+		return zero, false
+	}
+	return p.buf[0], true
+}
+
+// PeekN returns up to the next k items without consuming them. It returns
+// fewer than k only if the seq runs out, and none if k is negative.
+func (p *Peekable[T]) PeekN(k int) []T {
+	k = max(k, 0)
+	p.fill(k)
+	return slices.Clone(p.buf[:min(k, len(p.buf))])
+}
+
+// PushBack puts v in front of the remaining items, so the next call to Next
+// returns it. It works after the source is exhausted too.
+func (p *Peekable[T]) PushBack(v T) {
+	p.buf = slices.Insert(p.buf, 0, v)
+}
+
+// Stop releases the source. Items already buffered can still be read.
+func (p *Peekable[T]) Stop() {
+	p.done = true
+	p.stop()
+}
+
+// Seq yields the remaining items. Breaking out of the loop leaves the rest in
+// place for later calls.
+func (p *Peekable[T]) Seq() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := p.Next()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
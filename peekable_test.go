@@ -0,0 +1,80 @@
+package itertools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeekable(t *testing.T) {
+	p := NewPeekable(NewSeq(1, 2, 3))
+	defer p.Stop()
+
+	v, ok := p.Peek()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	v, ok = p.Next()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	assert.Equal(t, []int{2, 3}, p.PeekN(2))
+
+	p.PushBack(0)
+	assertSequenceMatch(t, p.Seq(), []int{0, 2, 3})
+}
+
+func TestPeekablePeekPastEnd(t *testing.T) {
+	p := NewPeekable(NewSeq(1, 2))
+	defer p.Stop()
+
+	assert.Equal(t, []int{1, 2}, p.PeekN(5))
+	assert.Equal(t, []int{}, p.PeekN(0))
+	assert.Equal(t, []int{}, p.PeekN(-1))
+
+	p.Next()
+	p.Next()
+
+	v, ok := p.Peek()
+	assert.False(t, ok)
+	assert.Equal(t, 0, v)
+
+	v, ok = p.Next()
+	assert.False(t, ok)
+	assert.Equal(t, 0, v)
+
+	assert.Equal(t, []int{}, p.PeekN(3))
+}
+
+func TestPeekablePushBackAfterExhaustion(t *testing.T) {
+	p := NewPeekable(NewSeq("a"))
+	defer p.Stop()
+
+	assertSequenceMatch(t, p.Seq(), []string{"a"})
+
+	p.PushBack("b")
+	p.PushBack("a")
+	assert.Equal(t, []string{"a", "b"}, p.PeekN(3))
+	assertSequenceMatch(t, p.Seq(), []string{"a", "b"})
+
+	_, ok := p.Next()
+	assert.False(t, ok)
+}
+
+func TestPeekableSeqBreak(t *testing.T) {
+	p := NewPeekable(Count())
+	defer p.Stop()
+
+	assertSequenceMatch(t, TakeWhile(func(x int) bool { return x < 3 }, p.Seq()), []int{0, 1, 2})
+
+	// TakeWhile consumed 3 to see that it was done
+	assertSequenceMatch(t, Take(p.Seq(), 2), []int{4, 5})
+}
+
+func TestPeekableStop(t *testing.T) {
+	p := NewPeekable(Count())
+	assert.Equal(t, []int{0, 1}, p.PeekN(2))
+	p.Stop()
+
+	assertSequenceMatch(t, p.Seq(), []int{0, 1})
+}
@@ -0,0 +1,44 @@
+package itertools
+
+import (
+	"iter"
+	"runtime/debug"
+	"sync"
+)
+
+var trackedPulls openIters
+
+// PullTracked is iter.Pull, but records the stack it was called from until
+// stop is called or next reports the end of s. UnstoppedPulls lists the ones
+// that are still open.
+func PullTracked[T any](s iter.Seq[T]) (func() (T, bool), func()) {
+	next, stop := iter.Pull(s)
+
+	id := trackedPulls.add(debug.Stack())
+	var once sync.Once
+	untrack := func() {
+		once.Do(func() { trackedPulls.remove(id) })
+	}
+
+	trackedNext := func() (T, bool) {
+		v, ok := next()
+		if !ok {
+			untrack()
+		}
+		return v, ok
+	}
+
+	trackedStop := func() {
+		untrack()
+		stop()
+	}
+
+	return trackedNext, trackedStop
+}
+
+// UnstoppedPulls returns an error listing the stack of every PullTracked call
+// that hasn't been stopped or run to the end. When built with the
+// itertoolsdebug tag, every pull made inside this package is tracked too.
+func UnstoppedPulls() error {
+	return trackedPulls.report("pulls")
+}
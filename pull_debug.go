@@ -0,0 +1,9 @@
+//go:build itertoolsdebug
+
+package itertools
+
+import "iter"
+
+func pull[T any](s iter.Seq[T]) (func() (T, bool), func()) {
+	return PullTracked(s)
+}
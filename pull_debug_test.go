@@ -0,0 +1,40 @@
+//go:build itertoolsdebug
+
+package itertools
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if err := UnstoppedPulls(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = 1
+	}
+	os.Exit(code)
+}
+
+func TestPullDebugTracksCombinators(t *testing.T) {
+	next, stop := iter.Pull(Take(Count(), 5))
+	next()
+
+	err := UnstoppedPulls()
+	assert.ErrorContains(t, err, "1 pulls never finished")
+	assert.ErrorContains(t, err, "itertools.Take")
+
+	stop()
+	assert.NoError(t, UnstoppedPulls())
+
+	pullNext, pullStop := PullZip3(Count(), Count(), Count())
+	pullNext()
+	assert.ErrorContains(t, UnstoppedPulls(), "3 pulls never finished")
+
+	pullStop()
+	assert.NoError(t, UnstoppedPulls())
+}
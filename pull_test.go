@@ -0,0 +1,32 @@
+package itertools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullTracked(t *testing.T) {
+	next, stop := PullTracked(Count())
+	v, ok := next()
+	assert.True(t, ok)
+	assert.Equal(t, 0, v)
+
+	err := UnstoppedPulls()
+	assert.ErrorContains(t, err, "1 pulls never finished")
+	assert.ErrorContains(t, err, "TestPullTracked")
+
+	stop()
+	stop()
+	assert.NoError(t, UnstoppedPulls())
+}
+
+func TestPullTrackedExhausted(t *testing.T) {
+	next, _ := PullTracked(NewSeq(1))
+	next()
+	assert.Error(t, UnstoppedPulls())
+
+	_, ok := next()
+	assert.False(t, ok)
+	assert.NoError(t, UnstoppedPulls())
+}
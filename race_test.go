@@ -0,0 +1,5 @@
+//go:build race
+
+package itertools
+
+const raceEnabled = true
@@ -0,0 +1,172 @@
+package itertools
+
+import (
+	"container/heap"
+	"iter"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// ReservoirSample picks a uniform sample of k items from s without knowing
+// its length up front. The sample keeps the order items were seen in.
+func ReservoirSample[T any](s iter.Seq[T], k int, rng *rand.Rand) []T {
+	if k <= 0 {
+		return []T{}
+	}
+
+	sample := make([]T, 0, k)
+	seen := make([]int, 0, k)
+
+	var i int
+	for v := range s {
+		if len(sample) < k {
+			sample = append(sample, v)
+			seen = append(seen, i)
+		} else if j := rng.IntN(i + 1); j < k {
+			sample[j] = v
+			seen[j] = i
+		}
+		i++
+	}
+
+	// reservoir slots are filled out of order, so sort by position in s
+	order := make([]int, len(sample))
+	for j := range order {
+		order[j] = j
+	}
+	slices.SortFunc(order, func(a, b int) int { return seen[a] - seen[b] })
+
+	return pick(sample, order)
+}
+
+type weightedItem[T any] struct {
+	key float64
+	val T
+}
+
+type weightedHeap[T any] []weightedItem[T]
+
+func (h weightedHeap[T]) Len() int           { return len(h) }
+func (h weightedHeap[T]) Less(i, j int) bool { return h[i].key < h[j].key }
+func (h weightedHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *weightedHeap[T]) Push(x any)        { *h = append(*h, x.(weightedItem[T])) }
+func (h *weightedHeap[T]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// WeightedSample picks k items from s without replacement, each with
+// probability proportional to weight, using the Efraimidis-Spirakis method.
+// Items with a non-positive weight are never picked. The sample is returned
+// in selection order.
+func WeightedSample[T any](s iter.Seq[T], k int, weight func(T) float64, rng *rand.Rand) []T {
+	if k <= 0 {
+		return []T{}
+	}
+
+	h := make(weightedHeap[T], 0, k)
+	for v := range s {
+		w := weight(v)
+		if w <= 0 {
+			continue
+		}
+
+		// log(u^(1/w)) keeps keys distinguishable for large weights
+		key := math.Log(1-rng.Float64()) / w
+		if len(h) < k {
+			heap.Push(&h, weightedItem[T]{key, v})
+		} else if key > h[0].key {
+			h[0] = weightedItem[T]{key, v}
+			heap.Fix(&h, 0)
+		}
+	}
+
+	out := make([]T, len(h))
+	for i := len(h) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(&h).(weightedItem[T]).val
+	}
+	return out
+}
+
+// RandomCombination is a uniform pick from Combinations(vals, r).
+func RandomCombination[T any](vals []T, r int, rng *rand.Rand) []T {
+	if r < 0 || r > len(vals) {
+		return nil
+	}
+
+	indices := rng.Perm(len(vals))[:r]
+	slices.Sort(indices)
+	return pick(vals, indices)
+}
+
+// RandomPermutation is a uniform pick from Permutations(vals, r).
+func RandomPermutation[T any](vals []T, r int, rng *rand.Rand) []T {
+	if r < 0 || r > len(vals) {
+		return nil
+	}
+
+	return pick(vals, rng.Perm(len(vals))[:r])
+}
+
+// RandomProduct is a uniform pick from Product(pool...).
+func RandomProduct[T any](rng *rand.Rand, pool ...[]T) []T {
+	prod := make([]T, len(pool))
+	for i, p := range pool {
+		if len(p) == 0 {
+			return nil
+		}
+		prod[i] = p[rng.IntN(len(p))]
+	}
+	return prod
+}
+
+// ShuffleBuffered approximates a shuffle of a stream that may not fit in
+// memory. It fills a buffer of bufSize items, then repeatedly yields a random
+// slot and refills it from s.
+func ShuffleBuffered[T any](s iter.Seq[T], bufSize int, rng *rand.Rand) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		buf := make([]T, 0, max(bufSize, 1))
+
+		for v := range s {
+			if len(buf) < cap(buf) {
+				buf = append(buf, v)
+				continue
+			}
+
+			i := rng.IntN(len(buf))
+			out := buf[i]
+			buf[i] = v
+			if !yield(out) {
+				return
+			}
+		}
+
+		rng.Shuffle(len(buf), func(i, j int) { buf[i], buf[j] = buf[j], buf[i] })
+		for _, v := range buf {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Shuffle collects a finite s and yields its items in a uniformly random
+// order.
+func Shuffle[T any](s iter.Seq[T], rng *rand.Rand) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		vals := slices.Collect(s)
+		for i := len(vals) - 1; i > 0; i-- {
+			j := rng.IntN(i + 1)
+			vals[i], vals[j] = vals[j], vals[i]
+		}
+
+		for _, v := range vals {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
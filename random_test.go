@@ -0,0 +1,180 @@
+package itertools
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(1, 2))
+}
+
+// assertUniform checks that every outcome in want was seen close to
+// trials/len(want) times.
+func assertUniform(t *testing.T, counts map[string]int, want []string, trials int) {
+	assert.Len(t, counts, len(want))
+
+	expected := float64(trials) / float64(len(want))
+	for _, k := range want {
+		assert.InDelta(t, expected, counts[k], expected*0.1, k)
+	}
+}
+
+func TestReservoirSample(t *testing.T) {
+	assert.Equal(t,
+		ReservoirSample(Take(Count(), 100), 5, newRand()),
+		ReservoirSample(Take(Count(), 100), 5, newRand()),
+	)
+
+	assert.Equal(t, []int{1, 2, 3}, ReservoirSample(NewSeq(1, 2, 3), 5, newRand()))
+	assert.Equal(t, []int{}, ReservoirSample(NewSeq(1, 2, 3), 0, newRand()))
+
+	sample := ReservoirSample(Take(Count(), 1000), 10, newRand())
+	assert.Len(t, sample, 10)
+	assert.True(t, slices.IsSorted(sample))
+
+	rng := newRand()
+	trials := 20000
+	counts := map[string]int{}
+	for range trials {
+		for _, v := range ReservoirSample(NewSeq("A", "B", "C", "D", "E"), 2, rng) {
+			counts[v]++
+		}
+	}
+	for k := range counts {
+		counts[k] /= 2
+	}
+	assertUniform(t, counts, []string{"A", "B", "C", "D", "E"}, trials)
+}
+
+func TestWeightedSample(t *testing.T) {
+	weight := func(s string) float64 { return float64(len(s)) }
+
+	assert.Equal(t,
+		WeightedSample(NewSeq("a", "bb", "ccc"), 2, weight, newRand()),
+		WeightedSample(NewSeq("a", "bb", "ccc"), 2, weight, newRand()),
+	)
+
+	assert.ElementsMatch(t,
+		[]string{"a", "bb", "ccc"},
+		WeightedSample(NewSeq("a", "bb", "ccc", ""), 5, weight, newRand()),
+	)
+
+	rng := newRand()
+	trials := 30000
+	counts := map[string]int{}
+	for range trials {
+		counts[WeightedSample(NewSeq("a", "bb", "ccc"), 1, weight, rng)[0]]++
+	}
+	assertUniform(t, map[string]int{
+		"a":   counts["a"] * 6,
+		"bb":  counts["bb"] * 3,
+		"ccc": counts["ccc"] * 2,
+	}, []string{"a", "bb", "ccc"}, trials*3)
+}
+
+func TestRandomCombination(t *testing.T) {
+	vals := []string{"A", "B", "C", "D"}
+	assert.Equal(t, RandomCombination(vals, 2, newRand()), RandomCombination(vals, 2, newRand()))
+	assert.Nil(t, RandomCombination(vals, 5, newRand()))
+
+	var want []string
+	for c := range Combinations(vals, 2) {
+		want = append(want, fmt.Sprint(c))
+	}
+
+	rng := newRand()
+	trials := 30000
+	counts := map[string]int{}
+	for range trials {
+		counts[fmt.Sprint(RandomCombination(vals, 2, rng))]++
+	}
+	assertUniform(t, counts, want, trials)
+}
+
+func TestRandomPermutation(t *testing.T) {
+	vals := []string{"A", "B", "C", "D"}
+	assert.Equal(t, RandomPermutation(vals, 2, newRand()), RandomPermutation(vals, 2, newRand()))
+	assert.Nil(t, RandomPermutation(vals, 5, newRand()))
+
+	var want []string
+	for p := range Permutations(vals, 2) {
+		want = append(want, fmt.Sprint(p))
+	}
+
+	rng := newRand()
+	trials := 30000
+	counts := map[string]int{}
+	for range trials {
+		counts[fmt.Sprint(RandomPermutation(vals, 2, rng))]++
+	}
+	assertUniform(t, counts, want, trials)
+}
+
+func TestRandomProduct(t *testing.T) {
+	pools := [][]string{{"A", "B", "C"}, {"x", "y"}}
+	assert.Equal(t, RandomProduct(newRand(), pools...), RandomProduct(newRand(), pools...))
+	assert.Nil(t, RandomProduct(newRand(), []string{"A"}, []string{}))
+
+	var want []string
+	for p := range Product(pools...) {
+		want = append(want, fmt.Sprint(p))
+	}
+
+	rng := newRand()
+	trials := 30000
+	counts := map[string]int{}
+	for range trials {
+		counts[fmt.Sprint(RandomProduct(rng, pools...))]++
+	}
+	assertUniform(t, counts, want, trials)
+}
+
+func TestShuffleBuffered(t *testing.T) {
+	assert.Equal(t,
+		slices.Collect(ShuffleBuffered(Take(Count(), 100), 10, newRand())),
+		slices.Collect(ShuffleBuffered(Take(Count(), 100), 10, newRand())),
+	)
+
+	for _, bufSize := range []int{0, 1, 10, 100, 500} {
+		got := slices.Collect(ShuffleBuffered(Take(Count(), 100), bufSize, newRand()))
+		slices.Sort(got)
+		assert.Equal(t, slices.Collect(Take(Count(), 100)), got, bufSize)
+	}
+
+	got := slices.Collect(ShuffleBuffered(Take(Count(), 100), 10, newRand()))
+	assert.NotEqual(t, slices.Collect(Take(Count(), 100)), got)
+
+	assertSequenceMatch(t, Take(ShuffleBuffered(Count(), 10, newRand()), 3),
+		slices.Collect(Take(ShuffleBuffered(Count(), 10, newRand()), 3)),
+	)
+}
+
+func TestShuffle(t *testing.T) {
+	assert.Equal(t,
+		slices.Collect(Shuffle(Take(Count(), 100), newRand())),
+		slices.Collect(Shuffle(Take(Count(), 100), newRand())),
+	)
+
+	got := slices.Collect(Shuffle(Take(Count(), 100), newRand()))
+	assert.NotEqual(t, slices.Collect(Take(Count(), 100)), got)
+	slices.Sort(got)
+	assert.Equal(t, slices.Collect(Take(Count(), 100)), got)
+
+	rng := newRand()
+	trials := 30000
+	counts := map[string]int{}
+	for range trials {
+		counts[fmt.Sprint(slices.Collect(Shuffle(NewSeq("A", "B", "C"), rng)))]++
+	}
+
+	var want []string
+	for p := range Permutations([]string{"A", "B", "C"}, 3) {
+		want = append(want, fmt.Sprint(p))
+	}
+	assertUniform(t, counts, want, trials)
+}
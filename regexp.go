@@ -0,0 +1,155 @@
+package itertools
+
+import (
+	"iter"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"unicode/utf8"
+)
+
+// UsesLeftContext reports whether re has an assertion about the text before
+// the point it's checked at: ^, \A, \b or \B. Such a regexp can't be
+// searched for in the middle of a text as if that were its start.
+func UsesLeftContext(re *regexp.Regexp) bool {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		// re compiled, so this is a POSIX regexp, which has no \b or \B
+		parsed, err = syntax.Parse(re.String(), syntax.POSIX)
+		if err != nil {
+			return true
+		}
+	}
+
+	var walk func(*syntax.Regexp) bool
+	walk = func(r *syntax.Regexp) bool {
+		switch r.Op {
+		case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+			return true
+		}
+		return slices.ContainsFunc(r.Sub, walk)
+	}
+	return walk(parsed)
+}
+
+// findAll yields the locations of the matches of re in s, with submatches if
+// asked for, exactly as the regexp FindAll methods would find them.
+//
+// Each match is searched for from the end of the last one. That only works
+// for a regexp that doesn't look behind where it's checked, so for one that
+// does, findAll asks FindAll for 1, 2, 4, ... matches instead, searching
+// about twice as far as needed and from the start of s each time.
+func findAll(re *regexp.Regexp, s string, submatches bool) iter.Seq[[]int] {
+	if UsesLeftContext(re) {
+		return findAllDoubling(func(n int) [][]int {
+			if submatches {
+				return re.FindAllStringSubmatchIndex(s, n)
+			}
+			return re.FindAllStringIndex(s, n)
+		})
+	}
+
+	return func(yield func([]int) bool) {
+		prevEnd := -1
+		for pos := 0; pos <= len(s); {
+			var loc []int
+			if submatches {
+				loc = re.FindStringSubmatchIndex(s[pos:])
+			} else {
+				loc = re.FindStringIndex(s[pos:])
+			}
+			if loc == nil {
+				return
+			}
+			for i := range loc {
+				if loc[i] >= 0 {
+					loc[i] += pos
+				}
+			}
+
+			// like FindAll, step over a rune after an empty match, and skip
+			// one right after the previous match
+			accept := true
+			if loc[1] == pos {
+				if loc[0] == prevEnd {
+					accept = false
+				}
+				if pos < len(s) {
+					_, size := utf8.DecodeRuneInString(s[pos:])
+					pos += size
+				} else {
+					pos++
+				}
+			} else {
+				pos = loc[1]
+			}
+			prevEnd = loc[1]
+
+			if accept && !yield(loc) {
+				return
+			}
+		}
+	}
+}
+
+func findAllDoubling(find func(n int) [][]int) iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		seen := 0
+		for n := 1; ; n *= 2 {
+			all := find(n)
+			for _, loc := range all[seen:] {
+				if !yield(loc) {
+					return
+				}
+			}
+			if len(all) < n {
+				return
+			}
+			seen = len(all)
+		}
+	}
+}
+
+// Matches lazily yields the matches of re in s, like re.FindAllString. A
+// regexp that uses ^, \A, \b or \B is searched for less lazily, see findAll.
+func Matches(re *regexp.Regexp, s string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for loc := range findAll(re, s, false) {
+			if !yield(s[loc[0]:loc[1]]) {
+				return
+			}
+		}
+	}
+}
+
+// SubmatchIndexes lazily yields the submatch indexes of each match of re in s,
+// like re.FindAllStringSubmatchIndex.
+func SubmatchIndexes(re *regexp.Regexp, s string) iter.Seq[[]int] {
+	return findAll(re, s, true)
+}
+
+// SplitRegexp lazily yields the pieces of s between matches of re, like
+// re.Split(s, -1).
+func SplitRegexp(re *regexp.Regexp, s string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if s == "" {
+			if re.String() != "" {
+				yield("")
+			}
+			return
+		}
+
+		beg, end := 0, 0
+		for loc := range findAll(re, s, false) {
+			end = loc[0]
+			if loc[1] != 0 && !yield(s[beg:end]) {
+				return
+			}
+			beg = loc[1]
+		}
+
+		if end != len(s) {
+			yield(s[beg:])
+		}
+	}
+}
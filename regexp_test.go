@@ -0,0 +1,107 @@
+package itertools
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var regexpCases = []struct{ expr, s string }{
+	{`\d+`, "a1 b22 c333 d"},
+	{`x*`, "abc"},
+	{`a*`, "baaab"},
+	{`\bx`, "xx x"},
+	{`^.`, "abc"},
+	{`(?m)^(\w)(\w*)`, "one\ntwo\nthree"},
+	{`,`, "a,b,,c,"},
+	{``, "abc"},
+	{`z`, ""},
+	{``, ""},
+	{`.`, "héllo"},
+}
+
+func TestMatches(t *testing.T) {
+	for _, c := range regexpCases {
+		re := regexp.MustCompile(c.expr)
+		assert.Equal(t, re.FindAllString(c.s, -1), slices.Collect(Matches(re, c.s)), "%q in %q", c.expr, c.s)
+	}
+}
+
+func TestSubmatchIndexes(t *testing.T) {
+	for _, c := range regexpCases {
+		re := regexp.MustCompile(c.expr)
+		assert.Equal(t, re.FindAllStringSubmatchIndex(c.s, -1), slices.Collect(SubmatchIndexes(re, c.s)), "%q in %q", c.expr, c.s)
+	}
+}
+
+func TestSplitRegexp(t *testing.T) {
+	for _, c := range regexpCases {
+		re := regexp.MustCompile(c.expr)
+		want := re.Split(c.s, -1)
+		assert.Equal(t, want, append([]string{}, slices.Collect(SplitRegexp(re, c.s))...), "%q in %q", c.expr, c.s)
+	}
+}
+
+func TestMatchesRandom(t *testing.T) {
+	exprs := []string{`a+`, `ab*`, `a*?`, `b|`, `(a|ab)(c|bcd)`, `[ab]c?$`, `(?m)^b`, `\bc`, `a\B`, `^.`, `\s*`}
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 2000 {
+		b := make([]byte, rng.IntN(12))
+		for i := range b {
+			b[i] = "abcd \n"[rng.IntN(6)]
+		}
+		s := string(b)
+
+		re := regexp.MustCompile(exprs[rng.IntN(len(exprs))])
+		assert.Equal(t, re.FindAllString(s, -1), slices.Collect(Matches(re, s)), "%q in %q", re, s)
+		assert.Equal(t, re.FindAllStringSubmatchIndex(s, -1), slices.Collect(SubmatchIndexes(re, s)), "%q in %q", re, s)
+	}
+}
+
+func TestMatchesOneAtATime(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector makes the regexp allocate on every search")
+	}
+
+	// matches are found one at a time, so taking 1000 allocates about one
+	// location each, rather than all the locations of every doubling round
+	re := regexp.MustCompile(`\d+`)
+	s := strings.Repeat("x 1 ", 100000)
+	allocs := testing.AllocsPerRun(5, func() {
+		for range Take(Matches(re, s), 1000) {
+		}
+	})
+	assert.Less(t, allocs, 1100.0)
+}
+
+func TestMatchesLazy(t *testing.T) {
+	// a regexp that looks behind it falls back to asking for 1, 2, 4, ...
+	// matches, so taking 10 only asks for the first 16
+	var calls []int
+	re := regexp.MustCompile(`\b\d+`)
+	s := strings.Repeat("x 1 ", 1000)
+	find := findAllDoubling(func(n int) [][]int {
+		calls = append(calls, n)
+		return re.FindAllStringIndex(s, n)
+	})
+
+	assert.Len(t, slices.Collect(Take(find, 10)), 10)
+	assert.Equal(t, []int{1, 2, 4, 8, 16}, calls)
+
+	assert.Equal(t, []string{"1", "1", "1"}, slices.Collect(Slice(Matches(re, s), 5, 8)))
+	assert.Equal(t, []string{"a", "b"}, slices.Collect(Take(SplitRegexp(regexp.MustCompile(`,`), "a,b,c,d"), 2)))
+}
+
+func TestUsesLeftContext(t *testing.T) {
+	for expr, want := range map[string]bool{
+		`a+`: false, `a$`: false, `(?m)a$`: false, `\z`: false,
+		`^a`: true, `(?m)^a`: true, `\Aa`: true, `\bx`: true, `a\B`: true, `(x|(?:\by))+`: true,
+	} {
+		assert.Equal(t, want, UsesLeftContext(regexp.MustCompile(expr)), expr)
+	}
+	assert.False(t, UsesLeftContext(regexp.MustCompilePOSIX(`a+$`)))
+}
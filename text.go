@@ -0,0 +1,213 @@
+package itertools
+
+import (
+	"bytes"
+	"io"
+	"iter"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Text is a string or byte slice. The seqs over Text yield pieces of their
+// input without copying it. Byte slice pieces are capped, so appending to one
+// never writes over the rest of the input.
+type Text interface {
+	~string | ~[]byte
+}
+
+// isString reports whether T is a string type. Checking it once up front lets
+// the helpers below convert between T and string or []byte only when that's
+// free.
+func isString[T Text]() bool {
+	return reflect.TypeFor[T]().Kind() == reflect.String
+}
+
+func sub[T Text](str bool, s T, i, j int) T {
+	if str {
+		return s[i:j]
+	}
+	return T([]byte(s)[i:j:j])
+}
+
+func index[T Text](str bool, s, sep T) int {
+	if str {
+		return strings.Index(string(s), string(sep))
+	}
+	return bytes.Index([]byte(s), []byte(sep))
+}
+
+func decodeRune[T Text](str bool, s T) (rune, int) {
+	if str {
+		return utf8.DecodeRuneInString(string(s))
+	}
+	return utf8.DecodeRune([]byte(s))
+}
+
+// SplitSeq lazily yields the pieces of s between each sep, like strings.Split.
+// An empty sep splits s into its UTF-8 sequences.
+func SplitSeq[T Text](s, sep T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		str := isString[T]()
+
+		if len(sep) == 0 {
+			for i := 0; i < len(s); {
+				_, size := decodeRune(str, s[i:])
+				if !yield(sub(str, s, i, i+size)) {
+					return
+				}
+				i += size
+			}
+			return
+		}
+
+		start := 0
+		for {
+			i := index(str, s[start:], sep)
+			if i < 0 {
+				break
+			}
+			if !yield(sub(str, s, start, start+i)) {
+				return
+			}
+			start += i + len(sep)
+		}
+		yield(sub(str, s, start, len(s)))
+	}
+}
+
+// FieldsFuncSeq lazily yields the runs of runes in s for which f is false,
+// like strings.FieldsFunc.
+func FieldsFuncSeq[T Text](s T, f func(rune) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		str := isString[T]()
+
+		start := -1
+		for i := 0; i < len(s); {
+			r, size := decodeRune(str, s[i:])
+			if f(r) {
+				if start >= 0 && !yield(sub(str, s, start, i)) {
+					return
+				}
+				start = -1
+			} else if start < 0 {
+				start = i
+			}
+			i += size
+		}
+
+		if start >= 0 {
+			yield(sub(str, s, start, len(s)))
+		}
+	}
+}
+
+// FieldsSeq lazily yields the runs of non-space runes in s, like
+// strings.Fields.
+func FieldsSeq[T Text](s T) iter.Seq[T] {
+	return FieldsFuncSeq(s, unicode.IsSpace)
+}
+
+// Words lazily yields the runs of letters and digits in s, skipping spaces
+// and punctuation.
+func Words[T Text](s T) iter.Seq[T] {
+	return FieldsFuncSeq(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// LinesSeq lazily yields the lines of s without their line endings. A final
+// line ending doesn't start another, empty, line.
+func LinesSeq[T Text](s T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		str := isString[T]()
+		nl := T("\n")
+
+		for start := 0; start < len(s); {
+			end := index(str, s[start:], nl)
+			next := start + end + 1
+			if end < 0 {
+				end, next = len(s)-start, len(s)
+			}
+
+			line := sub(str, s, start, start+end)
+			if len(line) > 0 && line[len(line)-1] == '\r' {
+				line = line[:len(line)-1]
+			}
+			if !yield(line) {
+				return
+			}
+			start = next
+		}
+	}
+}
+
+// RunesSeq lazily yields the runes of s. Invalid UTF-8 comes out as
+// utf8.RuneError, one byte at a time.
+func RunesSeq[T Text](s T) iter.Seq[rune] {
+	return func(yield func(rune) bool) {
+		str := isString[T]()
+
+		for i := 0; i < len(s); {
+			r, size := decodeRune(str, s[i:])
+			if !yield(r) {
+				return
+			}
+			i += size
+		}
+	}
+}
+
+// JoinString joins the items of seq with sep between them, like strings.Join.
+func JoinString[T Text](seq iter.Seq[T], sep string) string {
+	str := isString[T]()
+
+	var sb strings.Builder
+	first := true
+	for v := range seq {
+		if !first {
+			sb.WriteString(sep)
+		}
+		first = false
+
+		if str {
+			sb.WriteString(string(v))
+		} else {
+			sb.Write([]byte(v))
+		}
+	}
+	return sb.String()
+}
+
+// WriteTo writes the items of seq to w with sep between them. It returns the
+// number of bytes written and stops at the first error.
+func WriteTo[T Text](w io.Writer, seq iter.Seq[T], sep string) (int64, error) {
+	str := isString[T]()
+
+	var total int64
+	first := true
+	for v := range seq {
+		if !first {
+			n, err := io.WriteString(w, sep)
+			total += int64(n)
+			if err != nil {
+				return total, err
+			}
+		}
+		first = false
+
+		var n int
+		var err error
+		if str {
+			n, err = io.WriteString(w, string(v))
+		} else {
+			n, err = w.Write([]byte(v))
+		}
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,158 @@
+package itertools
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/astonm/go-itertools/itertest"
+	"github.com/stretchr/testify/assert"
+)
+
+var textInputs = []string{
+	"",
+	"a",
+	"a,b,,c,",
+	",",
+	"  hello   wörld\tfoo\n",
+	"one\ntwo\r\n\nthree",
+	"héllo, wörld! it's 2024...",
+	"bad \xff utf8",
+}
+
+func collectStrings[T Text](s iter.Seq[T]) []string {
+	out := []string{}
+	for v := range s {
+		out = append(out, string(v))
+	}
+	return out
+}
+
+func TestSplitSeq(t *testing.T) {
+	for _, s := range textInputs {
+		for _, sep := range []string{",", "", "l", "o, "} {
+			want := strings.Split(s, sep)
+			if s == "" && sep == "" {
+				want = []string{}
+			}
+			assert.Equal(t, want, collectStrings(SplitSeq(s, sep)), "%q by %q", s, sep)
+			assert.Equal(t, want, collectStrings(SplitSeq([]byte(s), []byte(sep))), "%q by %q", s, sep)
+		}
+	}
+}
+
+func TestFieldsSeq(t *testing.T) {
+	for _, s := range textInputs {
+		want := append([]string{}, strings.Fields(s)...)
+		assert.Equal(t, want, collectStrings(FieldsSeq(s)), "%q", s)
+		assert.Equal(t, want, collectStrings(FieldsSeq([]byte(s))), "%q", s)
+
+		isComma := func(r rune) bool { return r == ',' }
+		want = append([]string{}, strings.FieldsFunc(s, isComma)...)
+		assert.Equal(t, want, collectStrings(FieldsFuncSeq(s, isComma)), "%q", s)
+		assert.Equal(t, want, collectStrings(FieldsFuncSeq([]byte(s), isComma)), "%q", s)
+	}
+}
+
+func TestWords(t *testing.T) {
+	itertest.Equal(t, Words("héllo, wörld! it's 2024..."), []string{"héllo", "wörld", "it", "s", "2024"})
+	assert.Equal(t, []string{"a", "b"}, collectStrings(Words([]byte("--a--b--"))))
+	itertest.Equal(t, Words(" ,.! "), []string{})
+}
+
+func TestLinesSeq(t *testing.T) {
+	itertest.Equal(t, LinesSeq("one\ntwo\r\n\nthree"), []string{"one", "two", "", "three"})
+	itertest.Equal(t, LinesSeq("one\n"), []string{"one"})
+	itertest.Equal(t, LinesSeq("\n\n"), []string{"", ""})
+	itertest.Equal(t, LinesSeq(""), []string{})
+	assert.Equal(t, []string{"a", "b"}, collectStrings(LinesSeq([]byte("a\r\nb\n"))))
+}
+
+func TestRunesSeq(t *testing.T) {
+	for _, s := range textInputs {
+		assert.Equal(t, []rune(s), append([]rune{}, slices.Collect(RunesSeq(s))...), "%q", s)
+		assert.Equal(t, bytes.Runes([]byte(s)), append([]rune{}, slices.Collect(RunesSeq([]byte(s)))...), "%q", s)
+	}
+}
+
+func TestTextZeroCopy(t *testing.T) {
+	b := []byte("one two three")
+	var pieces [][]byte
+	for field := range FieldsSeq(b) {
+		pieces = append(pieces, field)
+	}
+	assert.Len(t, pieces, 3)
+
+	// pieces share b's memory, but can't be appended into the next piece
+	b[0] = 'O'
+	assert.Equal(t, "One", string(pieces[0]))
+	_ = append(pieces[0], '!')
+	assert.Equal(t, "One two three", string(b))
+
+	s := strings.Repeat("word ", 100)
+	allocs := testing.AllocsPerRun(10, func() {
+		for range SplitSeq(s, " ") {
+		}
+		for range FieldsSeq(b) {
+		}
+	})
+	assert.LessOrEqual(t, allocs, 4.0)
+}
+
+type myBytes []byte
+
+func TestTextNamedTypes(t *testing.T) {
+	var got []myBytes
+	for v := range SplitSeq(myBytes("a-b"), myBytes("-")) {
+		got = append(got, v)
+	}
+	assert.Equal(t, []myBytes{myBytes("a"), myBytes("b")}, got)
+}
+
+func TestTextTake(t *testing.T) {
+	itertest.Equal(t, Take(SplitSeq(strings.Repeat("x,", 1000), ","), 3), []string{"x", "x", "x"})
+	itertest.Equal(t, Slice(Words("a b c d e"), 1, 3), []string{"b", "c"})
+}
+
+func TestJoinString(t *testing.T) {
+	assert.Equal(t, "a-b-c", JoinString(SplitSeq("a,b,c", ","), "-"))
+	assert.Equal(t, "a b", JoinString(FieldsSeq([]byte(" a  b ")), " "))
+	assert.Equal(t, "", JoinString(NewSeq[string](), ","))
+}
+
+type limitedWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+var errFull = errors.New("full")
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.buf.Len()+len(p) > w.limit {
+		return 0, errFull
+	}
+	return w.buf.Write(p)
+}
+
+func TestWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := WriteTo(&buf, Words("hello, big world"), "\n")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), n)
+	assert.Equal(t, "hello\nbig\nworld", buf.String())
+
+	buf.Reset()
+	n, err = WriteTo(&buf, LinesSeq([]byte("a\nb")), ", ")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.Equal(t, "a, b", buf.String())
+
+	w := &limitedWriter{limit: 7}
+	n, err = WriteTo(w, NewSeq("abc", "def", "ghi"), "-")
+	assert.ErrorIs(t, err, errFull)
+	assert.Equal(t, int64(7), n)
+	assert.Equal(t, "abc-def", w.buf.String())
+}